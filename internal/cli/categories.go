@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bgoodwin24/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+var categorySubcommands = []string{"add", "rm", "ls"}
+
+// Manages the user's categories: add <name>, rm <name> and ls
+func HandlerCategory(s *State, cmd Command, user database.User) error {
+	sub, args := cmd.Args[0], cmd.Args[1:]
+	switch sub {
+	case "add", "rm":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: gator %s %s <name>", cmd.Name, sub)
+		}
+	case "ls":
+		if len(args) != 0 {
+			return fmt.Errorf("usage: gator %s ls", cmd.Name)
+		}
+	default:
+		return fmt.Errorf("unknown subcommand %q: use add, rm or ls", sub)
+	}
+
+	switch sub {
+	case "add":
+		_, err := s.DB.CreateCategory(cmd.Ctx, database.CreateCategoryParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			Name:      args[0],
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("category '%s' already exists", args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't create category: %w", err)
+		}
+		fmt.Printf("Created category '%s'\n", args[0])
+
+	case "rm":
+		deleted, err := s.DB.DeleteCategory(cmd.Ctx, database.DeleteCategoryParams{
+			UserID: user.ID,
+			Name:   args[0],
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't delete category: %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("no category named '%s'", args[0])
+		}
+		fmt.Printf("Deleted category '%s', its feeds are now uncategorized\n", args[0])
+
+	case "ls":
+		categories, err := s.DB.GetCategoriesForUser(cmd.Ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error fetching categories: %w", err)
+		}
+
+		t := newTable("id", "name", "feed_count", "created_at")
+		for _, category := range categories {
+			t.add(category.ID, category.Name, category.FeedCount, category.CreatedAt)
+		}
+		return render(cmd, t, func() {
+			if len(categories) == 0 {
+				fmt.Println("You don't have any categories")
+				return
+			}
+			for _, category := range categories {
+				fmt.Printf("%s (%d feeds)\n", category.Name, category.FeedCount)
+			}
+		})
+	}
+	return nil
+}
+
+// Subcommands first, then the user's categories for rm
+func completeCategory(s *State, cmd Command) ([]string, error) {
+	if len(cmd.Args) == 0 {
+		return categorySubcommands, nil
+	}
+	if cmd.Args[0] != "rm" {
+		return nil, nil
+	}
+
+	user, err := s.DB.GetUser(cmd.Ctx, s.Config.CurrentUserName)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.DB.GetCategoriesForUser(cmd.Ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get categories: %w", err)
+	}
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	return names, nil
+}
+
+// ID of the user's category with the given name, created if it doesn't
+// exist yet. An empty name means no category.
+func ensureCategory(s *State, cmd Command, user database.User, name string) (uuid.NullUUID, error) {
+	if name == "" {
+		return uuid.NullUUID{}, nil
+	}
+	category, err := s.DB.EnsureCategory(cmd.Ctx, database.EnsureCategoryParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("couldn't create category '%s': %w", name, err)
+	}
+	return uuid.NullUUID{UUID: category.ID, Valid: true}, nil
+}
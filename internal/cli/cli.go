@@ -4,40 +4,57 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Bgoodwin24/gator/internal/config"
 	"github.com/Bgoodwin24/gator/internal/database"
 	"github.com/Bgoodwin24/gator/internal/rss"
+	"github.com/Bgoodwin24/gator/internal/schedule"
 	"github.com/google/uuid"
 )
 
 type State struct {
-	DB     *database.Queries
+	DB *database.Queries
+	// Underlying connection, for queries that must run in a transaction
+	Conn   *sql.DB
 	Config *config.Config
 }
 
+// Runs fn with queries bound to a transaction, committed only if fn
+// succeeds
+func withTx(ctx context.Context, s *State, fn func(q *database.Queries) error) error {
+	tx, err := s.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(s.DB.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 type Command struct {
 	Name string
+	// Positional arguments, flags have already been parsed out
 	Args []string
-}
-
-type Commands struct {
-	CommandNames map[string]func(*State, Command) error
+	// Flags declared in the command's registration, see Command.Bool etc.
+	Flags *flag.FlagSet
+	// Cancelled when the process receives SIGINT or SIGTERM
+	Ctx context.Context
 }
 
 func HandlerLogin(s *State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("usage: %v <name>", cmd.Name)
-	}
-
 	// Attempt to get user from database
 	username := cmd.Args[0]
-	_, err := s.DB.GetUser(context.Background(), username)
+	_, err := s.DB.GetUser(cmd.Ctx, username)
 	if err != nil {
 		return fmt.Errorf("user does not exist")
 	}
@@ -52,11 +69,7 @@ func HandlerLogin(s *State, cmd Command) error {
 }
 
 func HandlerRegister(s *State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("username required")
-	}
-
-	user, err := s.DB.CreateUser(context.Background(), database.CreateUserParams{
+	user, err := s.DB.CreateUser(cmd.Ctx, database.CreateUserParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -78,89 +91,323 @@ func HandlerRegister(s *State, cmd Command) error {
 }
 
 func HandlerReset(s *State, cmd Command) error {
-	err := s.DB.Reset(context.Background())
+	// Feeds no longer go away with their users, delete them explicitly
+	err := withTx(cmd.Ctx, s, func(q *database.Queries) error {
+		if err := q.ResetFeeds(cmd.Ctx); err != nil {
+			return fmt.Errorf("couldn't delete feeds: %w", err)
+		}
+		if err := q.Reset(cmd.Ctx); err != nil {
+			return fmt.Errorf("couldn't delete users: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("couldn't delete users: %w", err)
+		return err
 	}
 	fmt.Println("Database reset successfully!")
 	return nil
 }
 
 func HandlerGetUsers(s *State, cmd Command) error {
-	users, err := s.DB.GetUsers(context.Background())
+	users, err := s.DB.GetUsers(cmd.Ctx)
 	if err != nil {
 		return fmt.Errorf("couldn't get users: %w", err)
 	}
-	for i, user := range users {
-		if i == 0 {
-			fmt.Printf("* %s (current)\n", user.Name)
-			continue
-		}
-		fmt.Printf("* %s\n", user.Name)
+
+	t := newTable("id", "name", "created_at", "current")
+	for _, user := range users {
+		t.add(user.ID, user.Name, user.CreatedAt, user.Name == s.Config.CurrentUserName)
 	}
-	return nil
+	return render(cmd, t, func() {
+		for _, user := range users {
+			if user.Name == s.Config.CurrentUserName {
+				fmt.Printf("* %s (current)\n", user.Name)
+				continue
+			}
+			fmt.Printf("* %s\n", user.Name)
+		}
+	})
+}
+
+// How long a claimed feed stays reserved for one aggregator. A process that
+// dies mid-scrape holds its feeds until the lease runs out.
+const feedClaimLease = 5 * time.Minute
+
+// Totals for the feeds scraped during a run
+type ScrapeStats struct {
+	Feeds    int
+	Failures int
+	Posts    int
 }
 
-func ScrapeFeeds(s *State) {
-	feed, err := s.DB.GetNextFeedToFetch(context.Background())
+// Claims up to concurrency of the stalest feeds and scrapes them in parallel.
+// Claims skip rows locked or leased by other agg processes on the same
+// database, so several aggregators can share the workload.
+func ScrapeFeeds(ctx context.Context, s *State, concurrency int) ScrapeStats {
+	var stats ScrapeStats
+	feeds, err := s.DB.ClaimFeedsToFetch(ctx, database.ClaimFeedsToFetchParams{
+		LeaseSeconds: int32(feedClaimLease.Seconds()),
+		BatchSize:    int32(concurrency),
+	})
 	if err != nil {
 		log.Println("couldn't get next feeds to fetch", err)
-		return
+		return stats
+	}
+	if len(feeds) == 0 {
+		log.Println("No feeds due to fetch")
+		return stats
+	}
+	log.Printf("Found %d feeds to fetch!", len(feeds))
+
+	jobs := make(chan database.Feed)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range min(concurrency, len(feeds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for feed := range jobs {
+				newPosts, err := ScrapeFeed(ctx, s, feed)
+				if err := s.DB.ReleaseFeedClaim(ctx, feed.ID); err != nil {
+					log.Printf("Couldn't release claim on feed %s: %v", feed.Name, err)
+				}
+
+				mu.Lock()
+				stats.Feeds++
+				stats.Posts += newPosts
+				if err != nil {
+					stats.Failures++
+				}
+				mu.Unlock()
+			}
+		}()
 	}
-	log.Println("Found a feed to fetch!")
-	ScrapeFeed(s.DB, feed)
+
+	for _, feed := range feeds {
+		jobs <- feed
+	}
+	close(jobs)
+	wg.Wait()
+	return stats
 }
 
-func ScrapeFeed(db *database.Queries, feed database.Feed) {
-	_, err := db.MarkFeedFetched(context.Background(), feed.ID)
+// Returns the number of new posts stored for the feed
+func ScrapeFeed(ctx context.Context, s *State, feed database.Feed) (int, error) {
+	_, err := s.DB.MarkFeedFetched(ctx, feed.ID)
 	if err != nil {
 		log.Printf("Couldn't mark feed %s fetched: %v", feed.Name, err)
-		return
+		return 0, err
 	}
 
-	feedData, err := rss.FetchFeed(context.Background(), feed.Url)
+	newPosts, feedData, err := collectFeed(ctx, s.DB, feed)
 	if err != nil {
 		log.Printf("couldn't collect feed %s fetched: %v:", feed.Name, err)
+		// Being interrupted isn't the feed's fault
+		if ctx.Err() == nil {
+			recordFeedFailure(ctx, s, feed, err)
+		}
+		return 0, err
+	}
+
+	err = s.DB.RecordFeedSuccess(ctx, feed.ID)
+	if err != nil {
+		log.Printf("Couldn't record success for feed %s: %v", feed.Name, err)
+	}
+	scheduleNextFetch(ctx, s.DB, feed, newPosts, feedData)
+	return newPosts, nil
+}
+
+// Stores a fetch made outside ScrapeFeed, like the one addfeed validates a
+// new feed with, and schedules the feed as if it had been scraped
+func storeFetchedFeed(ctx context.Context, s *State, feed database.Feed, result rss.FetchResult) (int, error) {
+	fetched, err := s.DB.MarkFeedFetched(ctx, feed.ID)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't mark feed %s fetched: %w", feed.Name, err)
+	}
+
+	newPosts := storePosts(ctx, s.DB, fetched, result)
+	err = s.DB.RecordFeedSuccess(ctx, fetched.ID)
+	if err != nil {
+		log.Printf("Couldn't record success for feed %s: %v", fetched.Name, err)
+	}
+	scheduleNextFetch(ctx, s.DB, fetched, newPosts, result.Feed)
+	return newPosts, nil
+}
+
+// Backs the feed off exponentially and disables it once it has failed
+// too many times in a row
+func recordFeedFailure(ctx context.Context, s *State, feed database.Feed, fetchErr error) {
+	failures := int(feed.ConsecutiveFailures) + 1
+	interval := time.Duration(feed.FetchInterval) * time.Second
+	next := schedule.Backoff(time.Now().UTC(), interval, failures)
+
+	updated, err := s.DB.RecordFeedFailure(ctx, database.RecordFeedFailureParams{
+		LastError:   sql.NullString{String: fetchErr.Error(), Valid: true},
+		NextFetchAt: sql.NullTime{Time: next, Valid: true},
+		MaxFailures: int32(s.Config.FeedFailureLimit()),
+		ID:          feed.ID,
+	})
+	if err != nil {
+		log.Printf("Couldn't record failure for feed %s: %v", feed.Name, err)
 		return
 	}
 
+	if updated.DisabledAt.Valid {
+		log.Printf("Feed %s disabled after %d consecutive failures", feed.Name, updated.ConsecutiveFailures)
+		return
+	}
+	log.Printf("Feed %s failed %d times in a row, retrying at %s", feed.Name, updated.ConsecutiveFailures, next.Format(time.RFC3339))
+}
+
+// Fetches a feed and stores new or edited posts, returning how many were new.
+// feedData is nil when the feed hasn't changed since the last fetch.
+func collectFeed(ctx context.Context, db *database.Queries, feed database.Feed) (int, *rss.RSSFeed, error) {
+	result, err := rss.FetchFeedConditional(ctx, feed.Url, feed.Etag.String, feed.LastModified.String)
+	if err != nil {
+		return 0, nil, err
+	}
+	if result.NotModified {
+		log.Printf("Feed %s not modified since last fetch", feed.Name)
+		// A 304 may come with fresh validators
+		if result.ETag != feed.Etag.String || result.LastModified != feed.LastModified.String {
+			saveCacheHeaders(ctx, db, feed, result)
+		}
+		return 0, nil, nil
+	}
+	return storePosts(ctx, db, feed, result), result.Feed, nil
+}
+
+// Stores the posts of a fetched feed along with its cache validators,
+// returning how many posts were new
+func storePosts(ctx context.Context, db *database.Queries, feed database.Feed, result rss.FetchResult) int {
+	feedData := result.Feed
+
+	newPosts, updatedPosts, failedPosts := 0, 0, 0
+	fetchedAt := time.Now().UTC()
 	for _, item := range feedData.Channel.Item {
-		fmt.Printf("Found post: %s\n", item.Title)
+		log.Printf("Found post: %s", item.Title)
 
-		publishedAt, err := time.Parse(time.RFC1123Z, item.PubDate)
+		// Keep posts with unparseable dates, flagged so browse can tell them apart
+		estimated := false
+		publishedAt, err := rss.ParseDate(item.PubDate)
 		if err != nil {
-			// Keep the fallbacks in case other feeds use different formats
-			publishedAt, err = time.Parse(time.RFC1123, item.PubDate)
-			if err != nil {
-				publishedAt, err = time.Parse(time.RFC822, item.PubDate)
-				if err != nil {
-					log.Printf("couldn't parse published at with any format: %v", err)
-					continue
-				}
-			}
+			log.Printf("couldn't parse published at for %s, using fetch time: %v", item.Title, err)
+			publishedAt = fetchedAt
+			estimated = true
 		}
 
-		_, err = db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:          uuid.New(),
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: item.Description,
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
+		guid := item.ID()
+		post, err := db.UpsertPost(ctx, database.UpsertPostParams{
+			ID:                   uuid.New(),
+			Title:                item.Title,
+			Url:                  item.Link,
+			Description:          item.Description,
+			PublishedAt:          publishedAt,
+			PublishedAtEstimated: estimated,
+			FeedID:               feed.ID,
+			Guid:                 guid,
+			ContentHash:          item.Hash(),
 		})
 		if err != nil {
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			// No row comes back when the stored post is unchanged
+			if errors.Is(err, sql.ErrNoRows) {
 				continue
 			}
 			log.Printf("Error saving post: %v", err)
+			failedPosts++
+			continue
+		}
+		// Only a new post can be a copy of one stored before guids, so
+		// feeds without such posts don't pay for the check
+		if post.Inserted && item.Link != "" && guid != item.Link {
+			if adoptLegacyPost(ctx, db, feed, post.ID, guid, item.Link) {
+				continue
+			}
+		}
+		if post.Inserted {
+			newPosts++
+		} else {
+			updatedPosts++
 		}
 	}
-	log.Printf("Feed %s collected, %v posts found", feed.Name, len(feedData.Channel.Item))
+
+	// Only remember the validators once the posts they cover are stored,
+	// otherwise the next fetch would get a 304 and never retry the failed ones
+	if failedPosts > 0 {
+		log.Printf("Feed %s: %d posts couldn't be saved, fetching it in full next time", feed.Name, failedPosts)
+		result.ETag, result.LastModified = "", ""
+	}
+	saveCacheHeaders(ctx, db, feed, result)
+	log.Printf("Feed %s collected, %v posts found, %v new, %v updated", feed.Name, len(feedData.Channel.Item), newPosts, updatedPosts)
+	return newPosts
+}
+
+// Posts stored before guids were tracked are keyed by their link. When a
+// new post turns out to be one of them, the copy is dropped and the old post
+// takes the item's guid, keeping its read and saved state. Reports whether
+// the post was an old one.
+func adoptLegacyPost(ctx context.Context, db *database.Queries, feed database.Feed, postID uuid.UUID, guid, link string) bool {
+	deleted, err := db.DeleteLegacyDuplicate(ctx, postID)
+	if err != nil {
+		log.Printf("Error checking post %s for an older copy: %v", link, err)
+		return false
+	}
+	if deleted == 0 {
+		return false
+	}
+
+	err = db.AdoptLegacyPostGUID(ctx, database.AdoptLegacyPostGUIDParams{
+		Guid:   guid,
+		FeedID: feed.ID,
+		Url:    link,
+	})
+	if err != nil {
+		// The old post keeps its link as guid and is matched again next fetch
+		log.Printf("Error updating guid of post %s: %v", link, err)
+	}
+	return true
+}
+
+func saveCacheHeaders(ctx context.Context, db *database.Queries, feed database.Feed, result rss.FetchResult) {
+	err := db.UpdateFeedCacheHeaders(ctx, database.UpdateFeedCacheHeadersParams{
+		ID:           feed.ID,
+		Etag:         sql.NullString{String: result.ETag, Valid: result.ETag != ""},
+		LastModified: sql.NullString{String: result.LastModified, Valid: result.LastModified != ""},
+	})
+	if err != nil {
+		log.Printf("Couldn't save cache headers for feed %s: %v", feed.Name, err)
+	}
+}
+
+// Lengthens the interval of feeds that rarely produce new posts and
+// shortens it for active ones, honoring the feed's <ttl> and <skipHours>
+func scheduleNextFetch(ctx context.Context, db *database.Queries, feed database.Feed, newPosts int, feedData *rss.RSSFeed) {
+	var ttl time.Duration
+	var skipHours []int
+	if feedData != nil {
+		ttl = feedData.TTL()
+		skipHours = feedData.SkipHours()
+	}
+
+	current := time.Duration(feed.FetchInterval) * time.Second
+	interval, next := schedule.Next(time.Now().UTC(), current, newPosts, ttl, skipHours)
+
+	err := db.UpdateFeedSchedule(ctx, database.UpdateFeedScheduleParams{
+		ID:            feed.ID,
+		FetchInterval: int32(interval.Seconds()),
+		NextFetchAt:   sql.NullTime{Time: next, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Couldn't schedule feed %s: %v", feed.Name, err)
+		return
+	}
+	log.Printf("Feed %s next due %s (every %v)", feed.Name, next.Format(time.RFC3339), interval)
 }
 
 func HandlerAgg(s *State, cmd Command) error {
-	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
-		return fmt.Errorf("usage: %v <time_between_reqs>", cmd.Name)
+	concurrency := cmd.Int("concurrency")
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
 	}
 
 	timeBetweenRequests, err := time.ParseDuration(cmd.Args[0])
@@ -168,71 +415,219 @@ func HandlerAgg(s *State, cmd Command) error {
 		return fmt.Errorf("invalid duration: %w", err)
 	}
 
-	log.Printf("Collecting feeds every %v\n", timeBetweenRequests)
+	log.Printf("Collecting feeds every %v with %d workers\n", timeBetweenRequests, concurrency)
 
-	ticker := time.NewTicker(timeBetweenRequests)
+	// A signal only stops new claims, feeds already being scraped run to
+	// completion so no post is cut off mid-insert
+	work := context.WithoutCancel(cmd.Ctx)
+	go func() {
+		<-cmd.Ctx.Done()
+		log.Println("Shutting down, waiting for in-flight feeds to finish (press Ctrl-C again to force)")
+	}()
 
-	for ; ; <-ticker.C {
-		ScrapeFeeds(s)
+	started := time.Now()
+	var total ScrapeStats
+	ticker := time.NewTicker(timeBetweenRequests)
+	defer ticker.Stop()
+
+	for {
+		stats := ScrapeFeeds(work, s, concurrency)
+		total.Feeds += stats.Feeds
+		total.Failures += stats.Failures
+		total.Posts += stats.Posts
+
+		select {
+		case <-cmd.Ctx.Done():
+			log.Printf("Scraped %d feeds (%d failed) and collected %d new posts in %v",
+				total.Feeds, total.Failures, total.Posts, time.Since(started).Round(time.Second))
+			return nil
+		case <-ticker.C:
+		}
 	}
 }
 
+// Adds a feed by URL. The URL is fetched first so only working feeds are
+// added, it may also be a website that links to its feed. Without a name
+// the feed's own title is used.
 func HandlerAddFeed(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 2 {
-		return fmt.Errorf("expected 2 arguments: name and url")
+	name, url := "", cmd.Args[0]
+	if len(cmd.Args) == 2 {
+		name, url = cmd.Args[0], cmd.Args[1]
 	}
 
-	name := cmd.Args[0]
-	url := cmd.Args[1]
+	var result *newFeed
+	if cmd.Bool("no-validate") {
+		if name == "" {
+			return fmt.Errorf("a name is required with --no-validate")
+		}
+	} else {
+		fetched, err := fetchNewFeed(s, cmd, url)
+		if err != nil {
+			return err
+		}
+		result = &fetched
+		url = fetched.URL
+
+		if name == "" {
+			name = strings.TrimSpace(fetched.Feed.Channel.Title)
+			if name == "" {
+				return fmt.Errorf("%s has no title, give the feed a name: gator %s <name> <url>", url, cmd.Name)
+			}
+		}
+	}
 
-	feed, err := s.DB.AddFeed(context.Background(), database.AddFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
-		Name:      name,
-		Url:       url,
-		UserID:    user.ID,
-	})
+	feed, err := addFollowedFeed(cmd.Ctx, s, user, name, url, uuid.NullUUID{})
 	if err != nil {
-		return fmt.Errorf("couldn't create feed: %w", err)
+		return err
 	}
 
-	fmt.Println("Feed created successfully:")
-	fmt.Println(feed)
-	fmt.Println()
-	fmt.Println("=====================================")
+	// Store the posts we already downloaded so browse has something to show
+	// before the next agg run
+	newPosts := 0
+	if result != nil {
+		newPosts, err = storeFetchedFeed(cmd.Ctx, s, feed, result.FetchResult)
+		if err != nil {
+			return err
+		}
+	}
 
-	return nil
+	t := newTable("id", "name", "url", "created_at", "posts")
+	t.add(feed.ID, feed.Name, feed.Url, feed.CreatedAt, newPosts)
+	return render(cmd, t, func() {
+		fmt.Println("Feed created and followed:")
+		fmt.Printf("Feed Name: %s, Feed URL: %s\n", feed.Name, feed.Url)
+		fmt.Printf("ID: %s\n", feed.ID)
+		if result != nil {
+			fmt.Printf("Stored %d posts\n", newPosts)
+		}
+		fmt.Println("=====================================")
+	})
+}
+
+// Creates a feed and makes the user its first follower. Feeds outlive the
+// user who added them, user_id only records who that was.
+func addFollowedFeed(ctx context.Context, s *State, user database.User, name, url string, categoryID uuid.NullUUID) (database.Feed, error) {
+	var feed database.Feed
+	err := withTx(ctx, s, func(q *database.Queries) error {
+		var err error
+		feed, err = q.AddFeed(ctx, database.AddFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			Name:      name,
+			Url:       url,
+			UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't create feed %s: %w", url, err)
+		}
+
+		_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			UserID:     user.ID,
+			FeedID:     feed.ID,
+			CategoryID: categoryID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to follow feed %s: %w", name, err)
+		}
+		return nil
+	})
+	return feed, err
 }
 
 func HandlerFeeds(s *State, cmd Command) error {
-	feeds, err := s.DB.FetchFeeds(context.Background())
+	if name := cmd.String("enable"); name != "" {
+		return enableFeed(s, cmd, name)
+	}
+	if cmd.Bool("broken") {
+		return listBrokenFeeds(s, cmd)
+	}
+
+	feeds, err := s.DB.FetchFeeds(cmd.Ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch feeds: %v", err)
 	}
 
-	if len(feeds) == 0 {
-		fmt.Println("No feeds found")
-		return nil
+	t := newTable("id", "name", "url", "user_name")
+	for _, feed := range feeds {
+		t.add(feed.FeedID, feed.FeedName, feed.FeedUrl, feed.UserName)
 	}
+	return render(cmd, t, func() {
+		if len(feeds) == 0 {
+			fmt.Println("No feeds found")
+			return
+		}
 
-	fmt.Printf("Found %d feeds:\n", len(feeds))
+		fmt.Printf("Found %d feeds:\n", len(feeds))
 
+		for _, feed := range feeds {
+			owner := feed.UserName.String
+			if !feed.UserName.Valid {
+				owner = "(deleted user)"
+			}
+			fmt.Printf("Feed Name: %s, Feed URL: %s, User Name: %s\n", feed.FeedName, feed.FeedUrl, owner)
+			fmt.Println("=====================================")
+		}
+	})
+}
+
+func listBrokenFeeds(s *State, cmd Command) error {
+	feeds, err := s.DB.GetBrokenFeeds(cmd.Ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch broken feeds: %v", err)
+	}
+
+	t := newTable("id", "name", "url", "consecutive_failures", "last_error", "last_success_at", "disabled_at")
 	for _, feed := range feeds {
-		fmt.Printf("Feed Name: %s, Feed URL: %s, User Name: %s\n", feed.FeedName, feed.FeedUrl, feed.UserName)
-		fmt.Println("=====================================")
+		t.add(feed.ID, feed.Name, feed.Url, feed.ConsecutiveFailures, feed.LastError, feed.LastSuccessAt, feed.DisabledAt)
 	}
-	return nil
+	return render(cmd, t, func() {
+		if len(feeds) == 0 {
+			fmt.Println("No broken feeds found")
+			return
+		}
+
+		fmt.Printf("Found %d broken feeds:\n", len(feeds))
+
+		for _, feed := range feeds {
+			fmt.Printf("Feed Name: %s, Feed URL: %s\n", feed.Name, feed.Url)
+			if feed.DisabledAt.Valid {
+				fmt.Printf("Disabled: %s\n", feed.DisabledAt.Time.Format("2006-01-02 15:04:05"))
+			}
+			fmt.Printf("Consecutive Failures: %d\n", feed.ConsecutiveFailures)
+			if feed.LastError.Valid {
+				fmt.Printf("Last Error: %s\n", feed.LastError.String)
+			}
+			if feed.LastSuccessAt.Valid {
+				fmt.Printf("Last Success: %s\n", feed.LastSuccessAt.Time.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Println("Last Success: never")
+			}
+			fmt.Println("=====================================")
+		}
+	})
 }
 
-func HandlerFollow(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 1 {
-		return fmt.Errorf("expected argument: feed name")
+// Turns a disabled feed back on and clears its failures, so it's fetched
+// on the next run
+func enableFeed(s *State, cmd Command, name string) error {
+	feeds, err := s.DB.EnableFeeds(cmd.Ctx, name)
+	if err != nil {
+		return fmt.Errorf("couldn't enable feed: %w", err)
+	}
+	if len(feeds) == 0 {
+		return fmt.Errorf("feed not found with name: %s", name)
 	}
+	for _, feed := range feeds {
+		fmt.Printf("Enabled '%s' (%s), it will be fetched on the next run\n", feed.Name, feed.Url)
+	}
+	return nil
+}
 
+func HandlerFollow(s *State, cmd Command, user database.User) error {
 	feedName := cmd.Args[0]
 
-	feeds, err := s.DB.FetchFeeds(context.Background())
+	feeds, err := s.DB.FetchFeeds(cmd.Ctx)
 	if err != nil {
 		return fmt.Errorf("error fetching feeds: %w", err)
 	}
@@ -250,50 +645,83 @@ func HandlerFollow(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("feed not found with name: %s", feedName)
 	}
 
-	_, err = s.DB.GetFeedFollow(context.Background(), database.GetFeedFollowParams{
+	category := cmd.String("category")
+	categoryID, err := ensureCategory(s, cmd, user, category)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.GetFeedFollow(cmd.Ctx, database.GetFeedFollowParams{
 		UserID: user.ID,
 		FeedID: feed.FeedID,
 	})
 	if err == nil {
-		return fmt.Errorf("you are already following '%s'", feed.FeedName)
+		// Following again with a category files the feed under it
+		if category == "" {
+			return fmt.Errorf("you are already following '%s'", feed.FeedName)
+		}
+		_, err = s.DB.SetFeedFollowCategory(cmd.Ctx, database.SetFeedFollowCategoryParams{
+			UserID:     user.ID,
+			FeedID:     feed.FeedID,
+			CategoryID: categoryID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set category: %w", err)
+		}
+		fmt.Printf("Moved '%s' to category '%s'\n", feed.FeedName, category)
+		return nil
 	}
 
 	if !errors.Is(err, sql.ErrNoRows) {
 		return fmt.Errorf("error checking feed follow: %w", err)
 	}
 
-	_, err = s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
-		UserID: user.ID,
-		FeedID: feed.FeedID,
+	_, err = s.DB.CreateFeedFollow(cmd.Ctx, database.CreateFeedFollowParams{
+		UserID:     user.ID,
+		FeedID:     feed.FeedID,
+		CategoryID: categoryID,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to follow feed: %w", err)
 	}
 
+	if category != "" {
+		fmt.Printf("Following '%s' in category '%s'\n", feed.FeedName, category)
+		return nil
+	}
 	fmt.Printf("Following '%s'\n", feed.FeedName)
 	return nil
 }
 
 func HandlerFollowing(s *State, cmd Command, user database.User) error {
-	followNames, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
+	followNames, err := s.DB.GetFeedFollowsForUser(cmd.Ctx, user.ID)
 	if err != nil {
 		return fmt.Errorf("error fetching feed follows: %v", err)
 	}
 
-	if len(followNames) == 0 {
-		fmt.Println("You are not following any feeds")
-		return nil
-	}
-
+	t := newTable("feed_id", "feed_name", "feed_url", "category", "followed_at")
 	for _, follow := range followNames {
-		fmt.Println(follow.FeedName)
+		t.add(follow.FeedID, follow.FeedName, follow.FeedUrl, follow.CategoryName, follow.CreatedAt)
 	}
-	return nil
+	return render(cmd, t, func() {
+		if len(followNames) == 0 {
+			fmt.Println("You are not following any feeds")
+			return
+		}
+
+		for _, follow := range followNames {
+			if follow.CategoryName.Valid {
+				fmt.Printf("%s [%s]\n", follow.FeedName, follow.CategoryName.String)
+				continue
+			}
+			fmt.Println(follow.FeedName)
+		}
+	})
 }
 
 func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User) error) func(*State, Command) error {
 	return func(s *State, cmd Command) error {
-		user, err := s.DB.GetUser(context.Background(), s.Config.CurrentUserName)
+		user, err := s.DB.GetUser(cmd.Ctx, s.Config.CurrentUserName)
 		if err != nil {
 			return err
 		}
@@ -302,14 +730,10 @@ func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User)
 }
 
 func HandlerUnfollow(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 1 {
-		return fmt.Errorf("feed name is required")
-	}
-
 	feedName := cmd.Args[0]
 	userID := user.ID
 
-	err := s.DB.Unfollow(context.Background(), database.UnfollowParams{
+	err := s.DB.Unfollow(cmd.Ctx, database.UnfollowParams{
 		UserID: userID,
 		Name:   feedName,
 	})
@@ -320,11 +744,20 @@ func HandlerUnfollow(s *State, cmd Command, user database.User) error {
 }
 
 func HandlerUpdate(s *State, cmd Command, user database.User) error {
-	ScrapeFeeds(s)
+	ScrapeFeeds(cmd.Ctx, s, 1)
 	return nil
 }
 
 func HandlerBrowse(s *State, cmd Command, user database.User) error {
+	all := cmd.Bool("all")
+	updated := cmd.Bool("updated")
+	feedName := cmd.String("feed")
+	category := cmd.String("category")
+	sortOrder := cmd.String("sort")
+	offset := cmd.Int("offset")
+	page := cmd.Int("page")
+	cursor := cmd.String("cursor")
+
 	limit := 2
 
 	if len(cmd.Args) > 0 {
@@ -334,22 +767,116 @@ func HandlerBrowse(s *State, cmd Command, user database.User) error {
 		}
 		limit = userLimit
 	}
+	if limit < 1 {
+		return fmt.Errorf("limit must be at least 1")
+	}
 
-	posts, err := s.DB.GetPostsForUsers(context.Background(), database.GetPostsForUsersParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
-	})
+	if sortOrder != "newest" && sortOrder != "oldest" {
+		return fmt.Errorf("invalid sort %q: use newest or oldest", sortOrder)
+	}
+
+	positions := 0
+	for _, set := range []bool{offset != 0, page != 0, cursor != ""} {
+		if set {
+			positions++
+		}
+	}
+	if positions > 1 {
+		return fmt.Errorf("use only one of --offset, --page and --cursor")
+	}
+	if offset < 0 || page < 0 {
+		return fmt.Errorf("offset and page can't be negative")
+	}
+	if page > 0 {
+		offset = (page - 1) * limit
+	}
+
+	params := database.GetPostsForUsersParams{
+		UserID:      user.ID,
+		UnreadOnly:  !all && !updated,
+		UpdatedOnly: updated,
+		FeedName:    sql.NullString{String: feedName, Valid: feedName != ""},
+		Category:    sql.NullString{String: category, Valid: category != ""},
+		OldestFirst: sortOrder == "oldest",
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	}
+	if since := cmd.String("since"); since != "" {
+		t, err := parseTimeFlag(since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if until := cmd.String("until"); until != "" {
+		t, err := parseTimeFlag(until)
+		if err != nil {
+			return err
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+	if cursor != "" {
+		publishedAt, id, err := decodeCursor(cursor)
+		if err != nil {
+			return err
+		}
+		params.CursorPublishedAt = sql.NullTime{Time: publishedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	posts, err := s.DB.GetPostsForUsers(cmd.Ctx, params)
 	if err != nil {
 		return fmt.Errorf("error getting posts: %v", err)
 	}
 
+	total, err := s.DB.CountPostsForUser(cmd.Ctx, database.CountPostsForUserParams{
+		UserID:      params.UserID,
+		UnreadOnly:  params.UnreadOnly,
+		UpdatedOnly: params.UpdatedOnly,
+		FeedName:    params.FeedName,
+		Since:       params.Since,
+		Until:       params.Until,
+		Category:    params.Category,
+	})
+	if err != nil {
+		return fmt.Errorf("error counting posts: %v", err)
+	}
+
+	t := newTable("id", "title", "url", "published_at", "published_at_estimated", "updated_at", "read_at", "feed", "description", "cursor")
+	for _, post := range posts {
+		updatedAt := sql.NullTime{Time: post.UpdatedAt, Valid: post.UpdatedAt.After(post.CreatedAt)}
+		t.add(post.ID, post.Title, post.Url, post.PublishedAt, post.PublishedAtEstimated, updatedAt,
+			post.ReadAt, post.FeedName, stripHTML(post.Description), encodeCursor(post.PublishedAt, post.ID))
+	}
+	return render(cmd, t, func() {
+		printPosts(posts, cursor, limit, offset, total)
+	})
+}
+
+func printPosts(posts []database.GetPostsForUsersRow, cursor string, limit, offset int, total int64) {
+	if len(posts) == 0 {
+		fmt.Println("No posts found")
+		return
+	}
+
 	for i, post := range posts {
 		if i > 0 {
 			fmt.Println("----------------------------------------")
 		}
+		fmt.Printf("ID: %s\n", post.ID)
 		fmt.Printf("Title: %s\n", post.Title)
 		fmt.Printf("Url: %s\n", post.Url)
-		fmt.Printf("Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05 -0700"))
+		if post.PublishedAtEstimated {
+			fmt.Printf("Published: %s (estimated)\n", post.PublishedAt.Format("2006-01-02 15:04:05 -0700"))
+		} else {
+			fmt.Printf("Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05 -0700"))
+		}
+		if post.UpdatedAt.After(post.CreatedAt) {
+			fmt.Printf("Updated: %s\n", post.UpdatedAt.Format("2006-01-02 15:04:05 -0700"))
+		}
+		if post.ReadAt.Valid {
+			fmt.Printf("Read: %s\n", post.ReadAt.Time.Format("2006-01-02 15:04:05 -0700"))
+		}
 		fmt.Printf("Feed: %s\n", post.FeedName)
 		if post.Description != "" {
 			if !strings.Contains(post.Description, "<p>Article URL:") {
@@ -361,7 +888,15 @@ func HandlerBrowse(s *State, cmd Command, user database.User) error {
 		}
 		fmt.Println()
 	}
-	return nil
+
+	if cursor == "" {
+		pages := (int(total) + limit - 1) / limit
+		fmt.Printf("Page %d of %d (%d posts)\n", offset/limit+1, pages, total)
+	}
+	if len(posts) == limit {
+		last := posts[len(posts)-1]
+		fmt.Printf("Next page: --cursor %s\n", encodeCursor(last.PublishedAt, last.ID))
+	}
 }
 
 func stripHTML(html string) string {
@@ -391,21 +926,6 @@ func stripHTML(html string) string {
 	return result
 }
 
-func (c *Commands) Register(name string, f func(*State, Command) error) {
-	if c.CommandNames == nil {
-		c.CommandNames = make(map[string]func(*State, Command) error)
-	}
-	c.CommandNames[name] = f
-}
-
-func (c *Commands) Run(s *State, cmd Command) error {
-	handler, exists := c.CommandNames[cmd.Name]
-	if !exists {
-		return fmt.Errorf("unknown command: %s", cmd.Name)
-	}
-	return handler(s, cmd)
-}
-
 func printUser(user database.User) {
 	fmt.Printf(" * ID:      %v\n", user.ID)
 	fmt.Printf(" * Name:      %v\n", user.Name)
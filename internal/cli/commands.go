@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Everything the registry knows about a command, used both to run it and
+// to generate help
+type CommandSpec struct {
+	Name        string
+	Description string
+	// Positional arguments as shown in help, e.g. "<name> [limit]"
+	Usage    string
+	Examples []string
+	// Declares the command's flags, read back with Command.Bool etc.
+	Flags func(fs *flag.FlagSet)
+	// Number of positional arguments accepted, MaxArgs < 0 means no limit
+	MinArgs int
+	MaxArgs int
+	// Left out of the command list in help
+	Hidden bool
+	// Runs without the config file or a database, the handler gets a nil
+	// State
+	Standalone bool
+	// Candidates for the command's positional arguments in shell completion
+	Complete func(s *State, cmd Command) ([]string, error)
+	Handler  func(*State, Command) error
+}
+
+type Commands struct {
+	specs map[string]CommandSpec
+	// Registration order, help lists commands in this order
+	order []string
+}
+
+func (c *Commands) Register(spec CommandSpec) {
+	if c.specs == nil {
+		c.specs = make(map[string]CommandSpec)
+	}
+	if _, exists := c.specs[spec.Name]; !exists {
+		c.order = append(c.order, spec.Name)
+	}
+	c.specs[spec.Name] = spec
+}
+
+// Parses flags and validates arguments against the command's spec before
+// calling its handler
+func (c *Commands) Run(s *State, cmd Command) error {
+	if cmd.Name == "--help" || cmd.Name == "-h" {
+		cmd.Name = "help"
+	}
+	spec, exists := c.specs[cmd.Name]
+	if !exists {
+		return fmt.Errorf("unknown command: %s (see 'gator help')", cmd.Name)
+	}
+
+	fs := spec.flagSet()
+	args, err := parseArgs(fs, cmd.Args)
+	if errors.Is(err, flag.ErrHelp) {
+		return c.writeCommandHelp(os.Stdout, spec.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("%v\nusage: %s", err, spec.usageLine())
+	}
+	if len(args) < spec.MinArgs || (spec.MaxArgs >= 0 && len(args) > spec.MaxArgs) {
+		return fmt.Errorf("usage: %s", spec.usageLine())
+	}
+
+	cmd.Args = args
+	cmd.Flags = fs
+	if err := checkOutput(cmd.Output()); err != nil {
+		return err
+	}
+	return spec.Handler(s, cmd)
+}
+
+// Reports whether Run needs a State for cmd. Help, standalone commands and
+// unknown commands don't, so callers can skip reading config and
+// connecting to the database.
+func (c *Commands) NeedsState(cmd Command) bool {
+	spec, exists := c.specs[cmd.Name]
+	if !exists || spec.Standalone {
+		return false
+	}
+	for _, arg := range cmd.Args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--help" || arg == "-help" || arg == "-h" {
+			return false
+		}
+	}
+	return true
+}
+
+// General help: every visible command with its description
+func (c *Commands) WriteHelp(w io.Writer) {
+	fmt.Fprintln(w, "gator is a command line RSS aggregator.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  gator [--output format] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range c.visibleNames() {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, c.specs[name].Description)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'gator help <command>' or 'gator <command> --help' for details.")
+}
+
+// Names of the commands shown in help, in registration order
+func (c *Commands) visibleNames() []string {
+	var names []string
+	for _, name := range c.order {
+		if !c.specs[name].Hidden {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (c *Commands) writeCommandHelp(w io.Writer, name string) error {
+	spec, exists := c.specs[name]
+	if !exists {
+		return fmt.Errorf("unknown command: %s (see 'gator help')", name)
+	}
+
+	fmt.Fprintf(w, "Usage: %s\n", spec.usageLine())
+	if spec.Description != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, spec.Description)
+	}
+
+	if flags := spec.flagList(); len(flags) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Flags:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, f := range flags {
+			name, usage := flag.UnquoteUsage(f)
+			line := "  --" + f.Name
+			if name != "" {
+				line += " " + name
+			}
+			if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+				usage += fmt.Sprintf(" (default %s)", f.DefValue)
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", line, usage)
+		}
+		tw.Flush()
+	}
+
+	if len(spec.Examples) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Examples:")
+		for _, example := range spec.Examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+	return nil
+}
+
+// Flags every command accepts
+func addGlobalFlags(fs *flag.FlagSet) {
+	fs.String("output", outputText, "output format for lists: "+strings.Join(outputFormats, ", "))
+}
+
+func (spec CommandSpec) flagSet() *flag.FlagSet {
+	fs := newFlagSet(Command{Name: spec.Name})
+	addGlobalFlags(fs)
+	if spec.Flags != nil {
+		spec.Flags(fs)
+	}
+	return fs
+}
+
+// Declared flags sorted by name
+func (spec CommandSpec) flagList() []*flag.Flag {
+	var flags []*flag.Flag
+	spec.flagSet().VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+func (spec CommandSpec) usageLine() string {
+	parts := []string{"gator", spec.Name, "[flags]"}
+	if spec.Usage != "" {
+		parts = append(parts, spec.Usage)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Flag accessors, they panic if the command's spec doesn't declare the flag
+func (cmd Command) Bool(name string) bool {
+	return cmd.Flags.Lookup(name).Value.(flag.Getter).Get().(bool)
+}
+
+func (cmd Command) Int(name string) int {
+	return cmd.Flags.Lookup(name).Value.(flag.Getter).Get().(int)
+}
+
+func (cmd Command) String(name string) string {
+	return cmd.Flags.Lookup(name).Value.(flag.Getter).Get().(string)
+}
+
+// Format chosen with --output
+func (cmd Command) Output() string {
+	if cmd.Flags == nil {
+		return outputText
+	}
+	return cmd.String("output")
+}
+
+// Builds the Command for a command line. Global flags, which all take a
+// value, may come before the command name and are passed on to the
+// command's own flag parsing.
+func NewCommand(ctx context.Context, args []string) Command {
+	var leading []string
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help" {
+		leading = append(leading, args[0])
+		name := strings.TrimLeft(args[0], "-")
+		args = args[1:]
+		if !strings.Contains(name, "=") && len(args) > 0 {
+			leading = append(leading, args[0])
+			args = args[1:]
+		}
+	}
+
+	cmd := Command{Ctx: ctx}
+	if len(args) > 0 {
+		cmd.Name = args[0]
+		cmd.Args = append(leading, args[1:]...)
+	}
+	return cmd
+}
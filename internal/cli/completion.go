@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Hidden command the completion scripts call for dynamic candidates, e.g.
+// feed names for follow
+const completeCommand = "__complete"
+
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// Prints the completion candidates of the command named in cmd.Args, one
+// per line. The words typed after the command name follow it, so the
+// command's Complete sees the arguments before the one being completed.
+func (c *Commands) complete(s *State, cmd Command) error {
+	spec, exists := c.specs[cmd.Args[0]]
+	if !exists || spec.Complete == nil {
+		return nil
+	}
+
+	// Nothing to offer after a flag that still needs its value, or once
+	// every argument is given
+	args, err := parseArgs(spec.flagSet(), cmd.Args[1:])
+	if err != nil || (spec.MaxArgs >= 0 && len(args) >= spec.MaxArgs) {
+		return nil
+	}
+
+	values, err := spec.Complete(s, Command{Name: spec.Name, Args: args, Ctx: cmd.Ctx})
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		fmt.Println(value)
+	}
+	return nil
+}
+
+func completeFeedNames(s *State, cmd Command) ([]string, error) {
+	feeds, err := s.DB.FetchFeeds(cmd.Ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get feeds: %w", err)
+	}
+	names := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		names = append(names, feed.FeedName)
+	}
+	return names, nil
+}
+
+func completeFollowedFeeds(s *State, cmd Command) ([]string, error) {
+	user, err := s.DB.GetUser(cmd.Ctx, s.Config.CurrentUserName)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.DB.GetFeedFollowsForUser(cmd.Ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	names := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		names = append(names, follow.FeedName)
+	}
+	return names, nil
+}
+
+func completeUserNames(s *State, cmd Command) ([]string, error) {
+	users, err := s.DB.GetUsers(cmd.Ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get users: %w", err)
+	}
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names, nil
+}
+
+// Command names and flags are written into the script, only argument
+// candidates are looked up at completion time
+func (c *Commands) writeCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		c.writeBashCompletion(w)
+	case "zsh":
+		c.writeZshCompletion(w)
+	case "fish":
+		c.writeFishCompletion(w)
+	default:
+		return fmt.Errorf("unsupported shell %q: use bash, zsh or fish", shell)
+	}
+	return nil
+}
+
+func (c *Commands) writeBashCompletion(w io.Writer) {
+	names := c.visibleNames()
+
+	fmt.Fprintln(w, "# bash completion for gator")
+	fmt.Fprintln(w, "_gator() {")
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, "    COMPREPLY=()")
+	fmt.Fprintln(w, "    if [[ $COMP_CWORD -eq 1 ]]; then")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    local cmd="${COMP_WORDS[1]}"`)
+	fmt.Fprintln(w, `    if [[ "$cur" == -* ]]; then`)
+	fmt.Fprintln(w, `        case "$cmd" in`)
+	for _, name := range names {
+		flags := []string{"--help"}
+		for _, f := range c.specs[name].flagList() {
+			flags = append(flags, "--"+f.Name)
+		}
+		fmt.Fprintf(w, "            %s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", name, strings.Join(flags, " "))
+	}
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    case "$cmd" in`)
+	fmt.Fprintf(w, "        %s) ;;\n", strings.Join(c.dynamicNames(), "|"))
+	fmt.Fprintln(w, "        *) return ;;")
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "    local value")
+	fmt.Fprintln(w, "    while IFS= read -r value; do")
+	io.WriteString(w, `        [[ "$value" == "$cur"* ]] && COMPREPLY+=("$(printf '%q' "$value")")`+"\n")
+	fmt.Fprintf(w, "    done < <(gator %s -- \"${COMP_WORDS[@]:1:COMP_CWORD-1}\" 2>/dev/null)\n", completeCommand)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -F _gator gator")
+}
+
+func (c *Commands) writeZshCompletion(w io.Writer) {
+	names := c.visibleNames()
+
+	fmt.Fprintln(w, "#compdef gator")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "_gator() {")
+	fmt.Fprintln(w, "    local -a commands flags values")
+	fmt.Fprintln(w, "    commands=(")
+	for _, name := range names {
+		fmt.Fprintf(w, "        %s\n", zshQuote(name+":"+zshEscapeColons(c.specs[name].Description)))
+	}
+	fmt.Fprintln(w, "    )")
+	fmt.Fprintln(w, "    if (( CURRENT == 2 )); then")
+	fmt.Fprintln(w, "        _describe 'command' commands")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    if [[ $PREFIX == -* ]]; then")
+	fmt.Fprintln(w, "        flags=('--help:show help for the command')")
+	fmt.Fprintln(w, "        case $words[2] in")
+	for _, name := range names {
+		flags := c.specs[name].flagList()
+		if len(flags) == 0 {
+			continue
+		}
+		quoted := make([]string, 0, len(flags))
+		for _, f := range flags {
+			quoted = append(quoted, zshQuote("--"+f.Name+":"+zshEscapeColons(f.Usage)))
+		}
+		fmt.Fprintf(w, "            %s) flags+=(%s) ;;\n", name, strings.Join(quoted, " "))
+	}
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "        _describe 'flag' flags")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    case $words[2] in")
+	fmt.Fprintf(w, "        %s)\n", strings.Join(c.dynamicNames(), "|"))
+	fmt.Fprintf(w, "            values=(\"${(@f)$(gator %s -- \"${(@)words[2,CURRENT-1]}\" 2>/dev/null)}\")\n", completeCommand)
+	fmt.Fprintln(w, "            compadd -a values")
+	fmt.Fprintln(w, "            ;;")
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	// Works both autoloaded from fpath and sourced directly
+	fmt.Fprintln(w, `if [[ "$funcstack[1]" == "_gator" ]]; then`)
+	fmt.Fprintln(w, `    _gator "$@"`)
+	fmt.Fprintln(w, "else")
+	fmt.Fprintln(w, "    compdef _gator gator")
+	fmt.Fprintln(w, "fi")
+}
+
+func (c *Commands) writeFishCompletion(w io.Writer) {
+	names := c.visibleNames()
+
+	fmt.Fprintln(w, "# fish completion for gator")
+	fmt.Fprintln(w, "complete -c gator -f")
+	for _, name := range names {
+		fmt.Fprintf(w, "complete -c gator -n __fish_use_subcommand -a %s -d %s\n", name, fishQuote(c.specs[name].Description))
+	}
+	for _, name := range names {
+		condition := fishQuote("__fish_seen_subcommand_from " + name)
+		fmt.Fprintf(w, "complete -c gator -n %s -l help -d %s\n", condition, fishQuote("show help for the command"))
+		for _, f := range c.specs[name].flagList() {
+			option := ""
+			if valueName, _ := flag.UnquoteUsage(f); valueName != "" {
+				option = " -r"
+			}
+			fmt.Fprintf(w, "complete -c gator -n %s -l %s%s -d %s\n", condition, f.Name, option, fishQuote(f.Usage))
+		}
+	}
+	for _, name := range c.dynamicNames() {
+		condition := fishQuote("__fish_seen_subcommand_from " + name)
+		fmt.Fprintf(w, "complete -c gator -n %s -a %s\n", condition, fishQuote(fmt.Sprintf("(gator %s -- (commandline -opc)[2..-1] 2>/dev/null)", completeCommand)))
+	}
+}
+
+// Visible commands whose arguments have completion candidates
+func (c *Commands) dynamicNames() []string {
+	var names []string
+	for _, name := range c.visibleNames() {
+		if c.specs[name].Complete != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func zshEscapeColons(s string) string {
+	return strings.ReplaceAll(s, ":", `\:`)
+}
+
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
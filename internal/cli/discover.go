@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Bgoodwin24/gator/internal/rss"
+)
+
+// Lists the feeds found behind a website URL
+func HandlerDiscover(s *State, cmd Command) error {
+	feeds, err := rss.Discover(cmd.Ctx, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't discover feeds: %w", err)
+	}
+
+	t := newTable("title", "url")
+	for _, feed := range feeds {
+		t.add(feed.Title, feed.URL)
+	}
+	return render(cmd, t, func() {
+		if len(feeds) == 0 {
+			fmt.Printf("No feeds found at %s\n", cmd.Args[0])
+			return
+		}
+		fmt.Printf("Found %d feeds:\n", len(feeds))
+		for _, feed := range feeds {
+			fmt.Printf("%s\n", describeFeed(feed))
+		}
+	})
+}
+
+// Resolves a website URL to a single feed URL, asking the user to choose
+// when the site has several feeds. Messages go to stderr so they don't mix
+// with --output.
+func discoverFeedURL(s *State, cmd Command, pageURL string) (string, error) {
+	feeds, err := rss.Discover(cmd.Ctx, pageURL)
+	if err != nil {
+		return "", fmt.Errorf("couldn't discover feeds: %w", err)
+	}
+
+	switch len(feeds) {
+	case 0:
+		return "", fmt.Errorf("no feed found at %s", pageURL)
+	case 1:
+		if feeds[0].URL != pageURL {
+			fmt.Fprintf(os.Stderr, "Found feed at %s\n", feeds[0].URL)
+		}
+		return feeds[0].URL, nil
+	}
+
+	options := make([]string, len(feeds))
+	for i, feed := range feeds {
+		options[i] = describeFeed(feed)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d feeds at %s:\n", len(feeds), pageURL)
+	choice, err := promptChoice("Which feed should be added?", options)
+	if err != nil {
+		return "", err
+	}
+	return feeds[choice].URL, nil
+}
+
+// A feed fetched before it's added, URL is where it was finally found
+type newFeed struct {
+	rss.FetchResult
+	URL string
+}
+
+// Fetches a feed that is about to be added. When the URL isn't a feed the
+// page is searched for one instead.
+func fetchNewFeed(s *State, cmd Command, url string) (newFeed, error) {
+	result, err := rss.FetchFeedConditional(cmd.Ctx, url, "", "")
+	if err == nil {
+		return newFeed{FetchResult: result, URL: url}, nil
+	}
+
+	feedURL, discoverErr := discoverFeedURL(s, cmd, url)
+	if discoverErr != nil {
+		return newFeed{}, fmt.Errorf("couldn't fetch feed %s: %w", url, err)
+	}
+	result, err = rss.FetchFeedConditional(cmd.Ctx, feedURL, "", "")
+	if err != nil {
+		return newFeed{}, fmt.Errorf("couldn't fetch feed %s: %w", feedURL, err)
+	}
+	return newFeed{FetchResult: result, URL: feedURL}, nil
+}
+
+func describeFeed(feed rss.DiscoveredFeed) string {
+	if feed.Title == "" {
+		return feed.URL
+	}
+	return fmt.Sprintf("%s - %s", feed.Title, feed.URL)
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Bgoodwin24/gator/internal/database"
+)
+
+var feedSubcommands = []string{"rename", "set-url", "rm"}
+
+// Edits or deletes a feed the user added: rename <feed> <name>,
+// set-url <feed> <url> and rm <feed>
+func HandlerFeed(s *State, cmd Command, user database.User) error {
+	sub, args := cmd.Args[0], cmd.Args[1:]
+	switch sub {
+	case "rename":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: gator %s rename <feed> <new_name>", cmd.Name)
+		}
+	case "set-url":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: gator %s set-url <feed> <url>", cmd.Name)
+		}
+	case "rm":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: gator %s rm <feed>", cmd.Name)
+		}
+	default:
+		return fmt.Errorf("unknown subcommand %q: use rename, set-url or rm", sub)
+	}
+
+	feed, err := findOwnedFeed(s, cmd, user, args[0])
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "rename":
+		renamed, err := s.DB.RenameFeed(cmd.Ctx, database.RenameFeedParams{
+			ID:   feed.ID,
+			Name: args[1],
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't rename feed: %w", err)
+		}
+		fmt.Printf("Renamed '%s' to '%s'\n", feed.Name, renamed.Name)
+
+	case "set-url":
+		url := args[1]
+		if !cmd.Bool("no-validate") {
+			fetched, err := fetchNewFeed(s, cmd, url)
+			if err != nil {
+				return err
+			}
+			url = fetched.URL
+		}
+		if url == feed.Url {
+			return fmt.Errorf("'%s' already uses %s", feed.Name, url)
+		}
+
+		existing, err := s.DB.GetFeedByURL(cmd.Ctx, url)
+		if err == nil {
+			return fmt.Errorf("%s is already added as '%s'", url, existing.Name)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error looking up feed %s: %w", url, err)
+		}
+
+		// Cache headers and failures belong to the old URL, so the feed is
+		// fetched from scratch on the next run
+		_, err = s.DB.SetFeedURL(cmd.Ctx, database.SetFeedURLParams{
+			ID:  feed.ID,
+			Url: url,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't change feed url: %w", err)
+		}
+		fmt.Printf("'%s' now fetches from %s\n", feed.Name, url)
+
+	case "rm":
+		stats, err := s.DB.GetFeedStats(cmd.Ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error counting feed followers: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "'%s' has %d followers and %d posts, all of them will be removed\n",
+			feed.Name, stats.FollowerCount, stats.PostCount)
+		// Deleting the feed is the one way saved posts go away
+		if stats.SavedCount > 0 {
+			fmt.Fprintf(os.Stderr, "This includes %d saved posts, they will be deleted for every user who saved them\n", stats.SavedCount)
+		}
+
+		if !cmd.Bool("yes") {
+			ok, err := confirm(fmt.Sprintf("Delete '%s'?", feed.Name))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("Nothing deleted")
+				return nil
+			}
+		}
+
+		if err := s.DB.DeleteFeed(cmd.Ctx, feed.ID); err != nil {
+			return fmt.Errorf("couldn't delete feed: %w", err)
+		}
+		fmt.Printf("Deleted '%s'\n", feed.Name)
+	}
+	return nil
+}
+
+// Subcommands first, then feed names
+func completeFeed(s *State, cmd Command) ([]string, error) {
+	switch len(cmd.Args) {
+	case 0:
+		return feedSubcommands, nil
+	case 1:
+		return completeFeedNames(s, cmd)
+	}
+	return nil, nil
+}
+
+// Looks a feed up by URL or name and checks the user may change it. Only
+// the user who added a feed may, unless that user was deleted, then any
+// of its followers can.
+func findOwnedFeed(s *State, cmd Command, user database.User, ref string) (database.Feed, error) {
+	feed, err := s.DB.GetFeedByURL(cmd.Ctx, ref)
+	if errors.Is(err, sql.ErrNoRows) {
+		var feeds []database.Feed
+		feeds, err = s.DB.GetFeedsByName(cmd.Ctx, ref)
+		if err != nil {
+			return database.Feed{}, fmt.Errorf("error looking up feed: %w", err)
+		}
+		switch len(feeds) {
+		case 0:
+			return database.Feed{}, fmt.Errorf("feed not found with name: %s", ref)
+		case 1:
+			feed = feeds[0]
+		default:
+			return database.Feed{}, fmt.Errorf("%d feeds are named '%s', use the feed's url instead", len(feeds), ref)
+		}
+	} else if err != nil {
+		return database.Feed{}, fmt.Errorf("error looking up feed: %w", err)
+	}
+
+	if feed.UserID.Valid {
+		if feed.UserID.UUID != user.ID {
+			return database.Feed{}, fmt.Errorf("'%s' was added by another user, only they can change it", feed.Name)
+		}
+		return feed, nil
+	}
+
+	_, err = s.DB.GetFeedFollow(cmd.Ctx, database.GetFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("'%s' has no owner, follow it to change it", feed.Name)
+	}
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("error checking feed follow: %w", err)
+	}
+	return feed, nil
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"flag"
+	"io"
+)
+
+// Flag set for a single command, errors are returned to Run instead of
+// exiting the process
+func newFlagSet(cmd Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// Parses flags wherever they appear among the arguments, the flag package
+// alone stops at the first positional argument. Everything after "--" is
+// positional, even when it starts with a dash. Returns the positional
+// arguments in order.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		// fs.Parse drops the "--" it stopped at, the rest must not be
+		// parsed again
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		args = rest
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
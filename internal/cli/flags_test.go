@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		all        bool
+		feed       string
+	}{
+		{"no args", nil, nil, false, ""},
+		{"flags first", []string{"--all", "10"}, []string{"10"}, true, ""},
+		{"flags last", []string{"10", "--all"}, []string{"10"}, true, ""},
+		{"mixed", []string{"a", "--feed", "HN", "b", "--all"}, []string{"a", "b"}, true, "HN"},
+		{"terminator", []string{"--", "-x", "--all"}, []string{"-x", "--all"}, false, ""},
+		{"flags before terminator", []string{"--all", "a", "--", "-x"}, []string{"a", "-x"}, true, ""},
+		{"flag value before terminator", []string{"--feed", "HN", "--", "-rust"}, []string{"-rust"}, false, "HN"},
+		{"stdin", []string{"-", "--all"}, []string{"-"}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			all := fs.Bool("all", false, "")
+			feed := fs.String("feed", "", "")
+
+			positional, err := parseArgs(fs, tt.args)
+			if err != nil {
+				t.Fatalf("parseArgs(%q) returned error: %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(positional, tt.positional) {
+				t.Errorf("positional = %q, want %q", positional, tt.positional)
+			}
+			if *all != tt.all || *feed != tt.feed {
+				t.Errorf("flags = --all=%v --feed=%q, want --all=%v --feed=%q", *all, *feed, tt.all, tt.feed)
+			}
+		})
+	}
+}
+
+func TestParseArgsUnknownFlag(t *testing.T) {
+	if _, err := parseArgs(newFlagSet(Command{Name: "test"}), []string{"a", "-rust"}); err == nil {
+		t.Errorf("parseArgs should reject an undefined flag")
+	}
+}
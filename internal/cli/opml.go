@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Bgoodwin24/gator/internal/database"
+	"github.com/Bgoodwin24/gator/internal/opml"
+)
+
+// Follows every feed in an OPML file, adding the ones gator doesn't know
+// yet. "-" reads the file from stdin.
+func HandlerImport(s *State, cmd Command, user database.User) error {
+	path := cmd.Args[0]
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't read %s: %w", path, err)
+	}
+
+	subs, err := opml.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	added, followed, skipped := 0, 0, 0
+	for _, sub := range subs {
+		feed, err := s.DB.GetFeedByURL(cmd.Ctx, sub.URL)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error looking up feed %s: %w", sub.URL, err)
+		}
+		feedExists := err == nil
+
+		if feedExists {
+			_, err = s.DB.GetFeedFollow(cmd.Ctx, database.GetFeedFollowParams{
+				UserID: user.ID,
+				FeedID: feed.ID,
+			})
+			if err == nil {
+				skipped++
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("error checking feed follow: %w", err)
+			}
+		}
+
+		categoryID, err := ensureCategory(s, cmd, user, sub.Category)
+		if err != nil {
+			return err
+		}
+
+		if !feedExists {
+			_, err = addFollowedFeed(cmd.Ctx, s, user, sub.Title, sub.URL, categoryID)
+			if err != nil {
+				return err
+			}
+			added++
+			continue
+		}
+
+		_, err = s.DB.CreateFeedFollow(cmd.Ctx, database.CreateFeedFollowParams{
+			UserID:     user.ID,
+			FeedID:     feed.ID,
+			CategoryID: categoryID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to follow %s: %w", feed.Name, err)
+		}
+		followed++
+	}
+
+	fmt.Printf("Imported %d feeds from %s: %d new, %d existing followed, %d already followed\n",
+		len(subs), path, added, followed, skipped)
+	return nil
+}
+
+// Writes the feeds the user follows as OPML 2.0, to stdout unless a file
+// is given
+func HandlerExport(s *State, cmd Command, user database.User) error {
+	follows, err := s.DB.GetFeedFollowsForUser(cmd.Ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching feed follows: %v", err)
+	}
+
+	subs := make([]opml.Subscription, 0, len(follows))
+	for _, follow := range follows {
+		subs = append(subs, opml.Subscription{
+			Title:    follow.FeedName,
+			URL:      follow.FeedUrl,
+			Category: follow.CategoryName.String,
+		})
+	}
+
+	data, err := opml.Marshal(fmt.Sprintf("%s's gator subscriptions", user.Name), subs, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.Args) == 0 {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(cmd.Args[0], data, 0o644); err != nil {
+		return fmt.Errorf("couldn't write %s: %w", cmd.Args[0], err)
+	}
+	fmt.Printf("Exported %d feeds to %s\n", len(subs), cmd.Args[0])
+	return nil
+}
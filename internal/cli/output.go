@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Formats accepted by the global --output flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+	outputTSV  = "tsv"
+)
+
+var outputFormats = []string{outputText, outputJSON, outputCSV, outputTSV}
+
+// Result of a list command in a shape every output format can render, one
+// row per record with values in column order
+type table struct {
+	columns []string
+	rows    [][]any
+}
+
+func newTable(columns ...string) *table {
+	return &table{columns: columns}
+}
+
+func (t *table) add(values ...any) {
+	t.rows = append(t.rows, values)
+}
+
+// Writes t in the format chosen with --output. Text layouts differ per
+// command, so for text the command's own printer runs instead.
+func render(cmd Command, t *table, text func()) error {
+	switch cmd.Output() {
+	case outputJSON:
+		return writeJSON(os.Stdout, t)
+	case outputCSV:
+		return writeCSV(os.Stdout, t)
+	case outputTSV:
+		return writeTSV(os.Stdout, t)
+	default:
+		text()
+		return nil
+	}
+}
+
+func checkOutput(format string) error {
+	for _, f := range outputFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output %q: use %s", format, strings.Join(outputFormats, ", "))
+}
+
+// A JSON array of objects keyed by column name, keys keep column order
+func writeJSON(w io.Writer, t *table) error {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range t.rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, value := range row {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			key, _ := json.Marshal(t.columns[j])
+			encoded, err := json.Marshal(jsonValue(value))
+			if err != nil {
+				return fmt.Errorf("couldn't encode %s: %w", t.columns[j], err)
+			}
+			buf.Write(key)
+			buf.WriteString(": ")
+			buf.Write(encoded)
+		}
+		buf.WriteString("}")
+	}
+	if len(t.rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeCSV(w io.Writer, t *table) error {
+	cw := csv.NewWriter(w)
+	cw.Write(t.columns)
+	for _, row := range t.rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = cellValue(value)
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Tab separated without quoting, tabs and newlines inside values are
+// backslash escaped so every record stays on one line
+func writeTSV(w io.Writer, t *table) error {
+	escape := strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+	var buf bytes.Buffer
+	buf.WriteString(strings.Join(t.columns, "\t") + "\n")
+	for _, row := range t.rows {
+		for i, value := range row {
+			if i > 0 {
+				buf.WriteString("\t")
+			}
+			buf.WriteString(escape.Replace(cellValue(value)))
+		}
+		buf.WriteString("\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Nullable columns become null, everything else encodes as is
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case sql.NullTime:
+		if !v.Valid {
+			return nil
+		}
+		return v.Time
+	case sql.NullString:
+		if !v.Valid {
+			return nil
+		}
+		return v.String
+	}
+	return value
+}
+
+func cellValue(value any) string {
+	switch v := jsonValue(value).(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Bgoodwin24/gator/internal/rss"
+	"github.com/google/uuid"
+)
+
+// Keyset cursors point just past the last post shown, ordered by publish
+// time then id so posts with equal timestamps are neither skipped nor
+// repeated
+func encodeCursor(publishedAt time.Time, id uuid.UUID) string {
+	raw := publishedAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("invalid cursor")
+	}
+
+	publishedAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("invalid cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, publishedAt)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("invalid cursor")
+	}
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("invalid cursor")
+	}
+	return t, postID, nil
+}
+
+// Accepts a date ("2024-05-01", RFC 3339, or any format feeds use) or a
+// duration meaning that long ago ("48h")
+func parseTimeFlag(value string) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+	t, err := rss.ParseDate(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use a date like 2024-05-01 or a duration like 48h", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Asks the user to pick one of options by number, returns its index. The
+// prompt is written to stderr, stdout is left for the command's output.
+func promptChoice(question string, options []string) (int, error) {
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stderr, "%s [1-%d]: ", question, len(options))
+		line, err := reader.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" && err != nil {
+			return 0, fmt.Errorf("no choice made")
+		}
+
+		n, convErr := strconv.Atoi(answer)
+		if convErr == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid choice %q", answer)
+		}
+		fmt.Fprintf(os.Stderr, "Please enter a number between 1 and %d\n", len(options))
+	}
+}
+
+// Asks a yes/no question on stderr, anything but yes counts as no
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	if answer == "" && err != nil {
+		return false, fmt.Errorf("no answer given")
+	}
+	return answer == "y" || answer == "yes", nil
+}
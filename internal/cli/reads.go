@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Bgoodwin24/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func HandlerRead(s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id: %v", err)
+	}
+
+	marked, err := s.DB.MarkPostRead(cmd.Ctx, database.MarkPostReadParams{
+		UserID: user.ID,
+		ID:     postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post read: %w", err)
+	}
+	if marked == 0 {
+		return fmt.Errorf("no post %s in the feeds you follow", postID)
+	}
+
+	fmt.Println("Marked post read")
+	return nil
+}
+
+func HandlerUnread(s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id: %v", err)
+	}
+
+	unmarked, err := s.DB.MarkPostUnread(cmd.Ctx, database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post unread: %w", err)
+	}
+	if unmarked == 0 {
+		return fmt.Errorf("post %s isn't marked read", postID)
+	}
+
+	fmt.Println("Marked post unread")
+	return nil
+}
+
+func HandlerMarkAllRead(s *State, cmd Command, user database.User) error {
+	var feedName sql.NullString
+	if len(cmd.Args) == 1 {
+		feedName = sql.NullString{String: cmd.Args[0], Valid: true}
+	}
+
+	marked, err := s.DB.MarkAllPostsRead(cmd.Ctx, database.MarkAllPostsReadParams{
+		UserID:   user.ID,
+		FeedName: feedName,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark posts read: %w", err)
+	}
+
+	fmt.Printf("Marked %d posts read\n", marked)
+	return nil
+}
@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"flag"
+	"os"
+)
+
+// Registry of every gator command, in the order help lists them
+func NewCommands() *Commands {
+	c := &Commands{}
+
+	c.Register(CommandSpec{
+		Name:        "help",
+		Description: "Show help for gator or a single command",
+		Usage:       "[command]",
+		MaxArgs:     1,
+		Standalone:  true,
+		Examples:    []string{"gator help", "gator help browse"},
+		Complete: func(s *State, cmd Command) ([]string, error) {
+			return c.visibleNames(), nil
+		},
+		Handler: func(s *State, cmd Command) error {
+			if len(cmd.Args) > 0 {
+				return c.writeCommandHelp(os.Stdout, cmd.Args[0])
+			}
+			c.WriteHelp(os.Stdout)
+			return nil
+		},
+	})
+	c.Register(CommandSpec{
+		Name:        "register",
+		Description: "Create a user and log in as them",
+		Usage:       "<username>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Examples:    []string{"gator register alice"},
+		Handler:     HandlerRegister,
+	})
+	c.Register(CommandSpec{
+		Name:        "login",
+		Description: "Switch the current user",
+		Usage:       "<username>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Examples:    []string{"gator login alice"},
+		Complete:    completeUserNames,
+		Handler:     HandlerLogin,
+	})
+	c.Register(CommandSpec{
+		Name:        "reset",
+		Description: "Delete every user and feed",
+		Handler:     HandlerReset,
+	})
+	c.Register(CommandSpec{
+		Name:        "users",
+		Description: "List registered users",
+		Handler:     HandlerGetUsers,
+	})
+	c.Register(CommandSpec{
+		Name:        "agg",
+		Description: "Fetch due feeds continuously until interrupted",
+		Usage:       "<time_between_reqs>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.Int("concurrency", 1, "number of feeds to fetch in parallel")
+		},
+		Examples: []string{"gator agg 1m", "gator agg 30s --concurrency 4"},
+		Handler:  HandlerAgg,
+	})
+	c.Register(CommandSpec{
+		Name:        "addfeed",
+		Description: "Add a feed by its URL or a website that links to it",
+		Usage:       "[name] <url>",
+		MinArgs:     1,
+		MaxArgs:     2,
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("no-validate", false, "add the feed without fetching it, a name is then required")
+		},
+		Examples: []string{
+			"gator addfeed https://news.ycombinator.com/rss",
+			"gator addfeed \"Hacker News\" https://news.ycombinator.com",
+			"gator addfeed --no-validate \"Hacker News\" https://news.ycombinator.com/rss",
+		},
+		Handler: MiddlewareLoggedIn(HandlerAddFeed),
+	})
+	c.Register(CommandSpec{
+		Name:        "discover",
+		Description: "List the feeds a website advertises",
+		Usage:       "<url>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Standalone:  true,
+		Examples:    []string{"gator discover https://go.dev/blog"},
+		Handler:     HandlerDiscover,
+	})
+	c.Register(CommandSpec{
+		Name:        "feeds",
+		Description: "List all feeds",
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("broken", false, "list feeds that are failing or disabled")
+			fs.String("enable", "", "turn a disabled feed back on, by name or url")
+		},
+		Examples: []string{
+			"gator feeds",
+			"gator feeds --broken",
+			"gator feeds --enable \"Hacker News\"",
+		},
+		Handler: HandlerFeeds,
+	})
+	c.Register(CommandSpec{
+		Name:        "feed",
+		Description: "Rename, move or delete a feed you added",
+		Usage:       "<rename|set-url|rm> <feed> [value]",
+		MinArgs:     2,
+		MaxArgs:     3,
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("no-validate", false, "set-url: change the url without fetching it")
+			fs.Bool("yes", false, "rm: delete without asking for confirmation")
+		},
+		Examples: []string{
+			"gator feed rename \"Hacker News\" HN",
+			"gator feed set-url HN https://news.ycombinator.com/rss",
+			"gator feed rm --yes HN",
+		},
+		Complete: completeFeed,
+		Handler:  MiddlewareLoggedIn(HandlerFeed),
+	})
+	c.Register(CommandSpec{
+		Name:        "follow",
+		Description: "Follow an existing feed",
+		Usage:       "<feed_name>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("category", "", "file the feed under a category, created if needed")
+		},
+		Examples: []string{
+			"gator follow \"Hacker News\"",
+			"gator follow --category tech \"Hacker News\"",
+		},
+		Complete: completeFeedNames,
+		Handler:  MiddlewareLoggedIn(HandlerFollow),
+	})
+	c.Register(CommandSpec{
+		Name:        "following",
+		Description: "List the feeds you follow",
+		Handler:     MiddlewareLoggedIn(HandlerFollowing),
+	})
+	c.Register(CommandSpec{
+		Name:        "unfollow",
+		Description: "Stop following a feed",
+		Usage:       "<feed_name>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Examples:    []string{"gator unfollow \"Hacker News\""},
+		Complete:    completeFollowedFeeds,
+		Handler:     MiddlewareLoggedIn(HandlerUnfollow),
+	})
+	c.Register(CommandSpec{
+		Name:        "category",
+		Description: "Add, remove or list the categories you file feeds under",
+		Usage:       "<add|rm|ls> [name]",
+		MinArgs:     1,
+		MaxArgs:     2,
+		Examples: []string{
+			"gator category add tech",
+			"gator category ls",
+			"gator category rm tech",
+		},
+		Complete: completeCategory,
+		Handler:  MiddlewareLoggedIn(HandlerCategory),
+	})
+	c.Register(CommandSpec{
+		Name:        "import",
+		Description: "Follow every feed in an OPML file, folders become categories",
+		Usage:       "<file.opml>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Examples:    []string{"gator import subscriptions.opml", "gator import - < subscriptions.opml"},
+		Handler:     MiddlewareLoggedIn(HandlerImport),
+	})
+	c.Register(CommandSpec{
+		Name:        "export",
+		Description: "Write the feeds you follow as OPML",
+		Usage:       "[file]",
+		MaxArgs:     1,
+		Examples:    []string{"gator export", "gator export subscriptions.opml"},
+		Handler:     MiddlewareLoggedIn(HandlerExport),
+	})
+	c.Register(CommandSpec{
+		Name:        "update",
+		Description: "Fetch the next due feed once",
+		Handler:     MiddlewareLoggedIn(HandlerUpdate),
+	})
+	c.Register(CommandSpec{
+		Name:        "browse",
+		Description: "Show posts from the feeds you follow, unread only by default",
+		Usage:       "[limit]",
+		MaxArgs:     1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("all", false, "include posts you've already read")
+			fs.Bool("updated", false, "only read posts edited since you read them")
+			fs.String("feed", "", "only posts from the named feed")
+			fs.String("category", "", "only posts from feeds in the category")
+			fs.String("since", "", "only posts published after a date or duration ago")
+			fs.String("until", "", "only posts published before a date or duration ago")
+			fs.String("sort", "newest", "newest or oldest first")
+			fs.Int("offset", 0, "skip this many posts")
+			fs.Int("page", 0, "show this page, counting from 1")
+			fs.String("cursor", "", "continue after the cursor printed by a previous page")
+		},
+		Examples: []string{
+			"gator browse 10",
+			"gator browse --all --feed \"Hacker News\" --since 48h",
+			"gator browse 20 --page 2 --sort oldest",
+		},
+		Handler: MiddlewareLoggedIn(HandlerBrowse),
+	})
+	c.Register(CommandSpec{
+		Name:        "read",
+		Description: "Mark a post as read",
+		Usage:       "<post_id>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler:     MiddlewareLoggedIn(HandlerRead),
+	})
+	c.Register(CommandSpec{
+		Name:        "unread",
+		Description: "Mark a post as unread",
+		Usage:       "<post_id>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler:     MiddlewareLoggedIn(HandlerUnread),
+	})
+	c.Register(CommandSpec{
+		Name:        "mark-all-read",
+		Description: "Mark every post you follow, or one feed's posts, as read",
+		Usage:       "[feed_name]",
+		MaxArgs:     1,
+		Examples:    []string{"gator mark-all-read", "gator mark-all-read \"Hacker News\""},
+		Complete:    completeFollowedFeeds,
+		Handler:     MiddlewareLoggedIn(HandlerMarkAllRead),
+	})
+	c.Register(CommandSpec{
+		Name:        "save",
+		Description: "Save a post to keep it after it scrolls out of browse",
+		Usage:       "<post_id>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler:     MiddlewareLoggedIn(HandlerSave),
+	})
+	c.Register(CommandSpec{
+		Name:        "unsave",
+		Description: "Remove a post from your saved posts",
+		Usage:       "<post_id>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler:     MiddlewareLoggedIn(HandlerUnsave),
+	})
+	c.Register(CommandSpec{
+		Name:        "saved",
+		Description: "List your saved posts",
+		Handler:     MiddlewareLoggedIn(HandlerSaved),
+	})
+	c.Register(CommandSpec{
+		Name:        "search",
+		Description: "Full-text search over stored posts",
+		Usage:       "<query>",
+		MinArgs:     1,
+		MaxArgs:     -1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("all-feeds", false, "search every feed, not only the ones you follow")
+			fs.Int("limit", 10, "maximum number of results")
+		},
+		Examples: []string{
+			"gator search golang generics",
+			"gator search --all-feeds --limit 5 postgres",
+			"gator search -- \"go generics\" -rust",
+		},
+		Handler: MiddlewareLoggedIn(HandlerSearch),
+	})
+	c.Register(CommandSpec{
+		Name:        "completion",
+		Description: "Print a shell completion script",
+		Usage:       "<bash|zsh|fish>",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Standalone:  true,
+		Examples: []string{
+			"source <(gator completion bash)",
+			"gator completion zsh > \"${fpath[1]}/_gator\"",
+			"gator completion fish > ~/.config/fish/completions/gator.fish",
+		},
+		Complete: func(s *State, cmd Command) ([]string, error) {
+			return completionShells, nil
+		},
+		Handler: func(s *State, cmd Command) error {
+			return c.writeCompletion(os.Stdout, cmd.Args[0])
+		},
+	})
+	c.Register(CommandSpec{
+		Name:        completeCommand,
+		Description: "Print completion candidates, used by the completion scripts",
+		Usage:       "<command> [args...]",
+		MinArgs:     1,
+		MaxArgs:     -1,
+		Hidden:      true,
+		Handler: func(s *State, cmd Command) error {
+			return c.complete(s, cmd)
+		},
+	})
+
+	return c
+}
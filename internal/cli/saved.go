@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Bgoodwin24/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func HandlerSave(s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id: %v", err)
+	}
+
+	saved, err := s.DB.SavePost(cmd.Ctx, database.SavePostParams{
+		UserID: user.ID,
+		ID:     postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't save post: %w", err)
+	}
+	if saved == 0 {
+		return fmt.Errorf("no post %s in the feeds you follow", postID)
+	}
+
+	fmt.Println("Post saved")
+	return nil
+}
+
+func HandlerUnsave(s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id: %v", err)
+	}
+
+	unsaved, err := s.DB.UnsavePost(cmd.Ctx, database.UnsavePostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't unsave post: %w", err)
+	}
+	if unsaved == 0 {
+		return fmt.Errorf("post %s isn't saved", postID)
+	}
+
+	fmt.Println("Post unsaved")
+	return nil
+}
+
+func HandlerSaved(s *State, cmd Command, user database.User) error {
+	posts, err := s.DB.GetSavedPosts(cmd.Ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting saved posts: %v", err)
+	}
+
+	t := newTable("id", "title", "url", "published_at", "saved_at", "feed")
+	for _, post := range posts {
+		t.add(post.ID, post.Title, post.Url, post.PublishedAt, post.SavedAt, post.FeedName)
+	}
+	return render(cmd, t, func() {
+		if len(posts) == 0 {
+			fmt.Println("No saved posts")
+			return
+		}
+
+		for i, post := range posts {
+			if i > 0 {
+				fmt.Println("----------------------------------------")
+			}
+			fmt.Printf("ID: %s\n", post.ID)
+			fmt.Printf("Title: %s\n", post.Title)
+			fmt.Printf("Url: %s\n", post.Url)
+			fmt.Printf("Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05 -0700"))
+			fmt.Printf("Saved: %s\n", post.SavedAt.Format("2006-01-02 15:04:05 -0700"))
+			fmt.Printf("Feed: %s\n", post.FeedName)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Bgoodwin24/gator/internal/database"
+)
+
+// Full-text search over post titles and descriptions. The query uses web
+// search syntax: quoted phrases, OR, and -word to exclude, which needs a
+// "--" first so the word isn't taken as a flag.
+func HandlerSearch(s *State, cmd Command, user database.User) error {
+	query := searchQuery(cmd.Args)
+	limit := cmd.Int("limit")
+	if limit < 1 {
+		return fmt.Errorf("limit must be at least 1")
+	}
+
+	posts, err := s.DB.SearchPosts(cmd.Ctx, database.SearchPostsParams{
+		Query:    query,
+		AllFeeds: cmd.Bool("all-feeds"),
+		UserID:   user.ID,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("error searching posts: %v", err)
+	}
+
+	t := newTable("id", "title", "url", "published_at", "feed", "rank")
+	for _, post := range posts {
+		t.add(post.ID, post.Title, post.Url, post.PublishedAt, post.FeedName, post.Rank)
+	}
+	return render(cmd, t, func() {
+		if len(posts) == 0 {
+			fmt.Printf("No posts found matching %q\n", query)
+			return
+		}
+
+		for i, post := range posts {
+			if i > 0 {
+				fmt.Println("----------------------------------------")
+			}
+			fmt.Printf("ID: %s\n", post.ID)
+			fmt.Printf("Title: %s\n", post.Title)
+			fmt.Printf("Url: %s\n", post.Url)
+			fmt.Printf("Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05 -0700"))
+			fmt.Printf("Feed: %s\n", post.FeedName)
+		}
+	})
+}
+
+// Joins the arguments into one query. An argument the shell kept together,
+// like "go generics", is searched as a phrase.
+func searchQuery(args []string) string {
+	terms := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " \t") && !strings.Contains(arg, `"`) {
+			arg = `"` + arg + `"`
+		}
+		terms[i] = arg
+	}
+	return strings.Join(terms, " ")
+}
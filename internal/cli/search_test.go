@@ -0,0 +1,23 @@
+package cli
+
+import "testing"
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"words", []string{"golang", "generics"}, "golang generics"},
+		{"phrase", []string{"go generics", "-rust"}, `"go generics" -rust`},
+		{"already quoted", []string{`"go generics" -rust`}, `"go generics" -rust`},
+		{"or", []string{"postgres", "OR", "mysql"}, "postgres OR mysql"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchQuery(tt.args); got != tt.want {
+				t.Errorf("searchQuery(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -9,9 +9,21 @@ import (
 
 const configFileName = ".gatorconfig.json"
 
+// Used when max_feed_failures isn't set in the config file
+const defaultMaxFeedFailures = 10
+
 type Config struct {
 	DBUrl           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	MaxFeedFailures int    `json:"max_feed_failures,omitempty"`
+}
+
+// Number of consecutive fetch failures before a feed is disabled
+func (cfg *Config) FeedFailureLimit() int {
+	if cfg.MaxFeedFailures <= 0 {
+		return defaultMaxFeedFailures
+	}
+	return cfg.MaxFeedFailures
 }
 
 // Sets username in Config struct
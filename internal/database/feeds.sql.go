@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,7 +23,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
 `
 
 type AddFeedParams struct {
@@ -31,7 +32,7 @@ type AddFeedParams struct {
 	UpdatedAt time.Time
 	Name      string
 	Url       string
-	UserID    uuid.UUID
+	UserID    uuid.NullUUID
 }
 
 func (q *Queries) AddFeed(ctx context.Context, arg AddFeedParams) (Feed, error) {
@@ -52,19 +53,89 @@ func (q *Queries) AddFeed(ctx context.Context, arg AddFeedParams) (Feed, error)
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ClaimedUntil,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET claimed_until = NOW() + $1::int * INTERVAL '1 second'
+WHERE id IN (
+    SELECT id
+    FROM feeds
+    WHERE disabled_at IS NULL
+    AND (claimed_until IS NULL OR claimed_until < NOW())
+    AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    ORDER BY next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+`
+
+type ClaimFeedsToFetchParams struct {
+	LeaseSeconds int32
+	BatchSize    int32
+}
+
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ClaimedUntil,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :many
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-    VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, category_id)
+    VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3)
+    RETURNING id, created_at, updated_at, user_id, feed_id, category_id
 )
 
 SELECT
-    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.category_id,
     feeds.name AS feed_name,
     users.name AS user_name
 FROM inserted_feed_follow
@@ -75,22 +146,24 @@ ON inserted_feed_follow.user_id = users.id
 `
 
 type CreateFeedFollowParams struct {
-	UserID uuid.UUID
-	FeedID uuid.UUID
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	CategoryID uuid.NullUUID
 }
 
 type CreateFeedFollowRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	CategoryID uuid.NullUUID
+	FeedName   string
+	UserName   string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) ([]CreateFeedFollowRow, error) {
-	rows, err := q.db.QueryContext(ctx, createFeedFollow, arg.UserID, arg.FeedID)
+	rows, err := q.db.QueryContext(ctx, createFeedFollow, arg.UserID, arg.FeedID, arg.CategoryID)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +177,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.CategoryID,
 			&i.FeedName,
 			&i.UserName,
 		); err != nil {
@@ -120,6 +194,67 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 	return items, nil
 }
 
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const enableFeeds = `-- name: EnableFeeds :many
+UPDATE feeds
+SET disabled_at = NULL,
+consecutive_failures = 0,
+last_error = NULL,
+next_fetch_at = NULL,
+updated_at = NOW()
+WHERE name = $1 OR url = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+`
+
+func (q *Queries) EnableFeeds(ctx context.Context, name string) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, enableFeeds, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ClaimedUntil,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const fetchFeeds = `-- name: FetchFeeds :many
 SELECT
     feeds.id AS feed_id,
@@ -127,7 +262,7 @@ SELECT
     feeds.url AS feed_url,
     users.name AS user_name
 FROM feeds
-JOIN users
+LEFT JOIN users
 ON users.id = feeds.user_id
 `
 
@@ -135,7 +270,7 @@ type FetchFeedsRow struct {
 	FeedID   uuid.UUID
 	FeedName string
 	FeedUrl  string
-	UserName string
+	UserName sql.NullString
 }
 
 func (q *Queries) FetchFeeds(ctx context.Context) ([]FetchFeedsRow, error) {
@@ -166,8 +301,85 @@ func (q *Queries) FetchFeeds(ctx context.Context) ([]FetchFeedsRow, error) {
 	return items, nil
 }
 
+const getBrokenFeeds = `-- name: GetBrokenFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY disabled_at IS NULL, consecutive_failures DESC
+`
+
+func (q *Queries) GetBrokenFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getBrokenFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ClaimedUntil,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedByURL = `-- name: GetFeedByURL :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+FROM feeds
+WHERE url = $1
+`
+
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByURL, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ClaimedUntil,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
 const getFeedFollow = `-- name: GetFeedFollow :one
-SELECT id, created_at, updated_at, user_id, feed_id
+SELECT id, created_at, updated_at, user_id, feed_id, category_id
 FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2
 `
@@ -186,31 +398,40 @@ func (q *Queries) GetFeedFollow(ctx context.Context, arg GetFeedFollowParams) (F
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.CategoryID,
 	)
 	return i, err
 }
 
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT 
-    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
+    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.category_id,
     feeds.name AS feed_name,
-    users.name AS user_name
+    feeds.url AS feed_url,
+    users.name AS user_name,
+    categories.name AS category_name
 FROM feed_follows
 INNER JOIN feeds
 ON feed_follows.feed_id = feeds.id
 INNER JOIN users
 ON feed_follows.user_id = users.id
+LEFT JOIN categories
+ON feed_follows.category_id = categories.id
 WHERE feed_follows.user_id = $1
+ORDER BY categories.name NULLS FIRST, feeds.name
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID
+	FeedID       uuid.UUID
+	CategoryID   uuid.NullUUID
+	FeedName     string
+	FeedUrl      string
+	UserName     string
+	CategoryName sql.NullString
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -228,8 +449,11 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.CategoryID,
 			&i.FeedName,
+			&i.FeedUrl,
 			&i.UserName,
+			&i.CategoryName,
 		); err != nil {
 			return nil, err
 		}
@@ -244,15 +468,88 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+const getFeedStats = `-- name: GetFeedStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS follower_count,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = $1) AS post_count,
+    (
+        SELECT COUNT(*)
+        FROM saved_posts
+        JOIN posts ON posts.id = saved_posts.post_id
+        WHERE posts.feed_id = $1
+    ) AS saved_count
+`
+
+type GetFeedStatsRow struct {
+	FollowerCount int64
+	PostCount     int64
+	SavedCount    int64
+}
+
+func (q *Queries) GetFeedStats(ctx context.Context, feedID uuid.UUID) (GetFeedStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedStats, feedID)
+	var i GetFeedStatsRow
+	err := row.Scan(&i.FollowerCount, &i.PostCount, &i.SavedCount)
+	return i, err
+}
+
+const getFeedsByName = `-- name: GetFeedsByName :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
 FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1
+WHERE name = $1
+ORDER BY created_at
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+func (q *Queries) GetFeedsByName(ctx context.Context, name string) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsByName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ClaimedUntil,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFeedFetched = `-- name: MarkFeedFetched :one
+UPDATE feeds
+SET last_fetched_at = NOW(),
+updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+`
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, markFeedFetched, id)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -262,20 +559,180 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ClaimedUntil,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :one
+const recordFeedFailure = `-- name: RecordFeedFailure :one
 UPDATE feeds
-SET last_fetched_at = NOW(),
+SET last_error = $1,
+consecutive_failures = consecutive_failures + 1,
+next_fetch_at = $2,
+disabled_at = CASE
+    WHEN consecutive_failures + 1 >= $3::int THEN NOW()
+    ELSE disabled_at
+END
+WHERE id = $4
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+`
+
+type RecordFeedFailureParams struct {
+	LastError   sql.NullString
+	NextFetchAt sql.NullTime
+	MaxFailures int32
+	ID          uuid.UUID
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedFailure,
+		arg.LastError,
+		arg.NextFetchAt,
+		arg.MaxFailures,
+		arg.ID,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ClaimedUntil,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_error = NULL,
+consecutive_failures = 0,
+last_success_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordFeedSuccess(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, id)
+	return err
+}
+
+const releaseFeedClaim = `-- name: ReleaseFeedClaim :exec
+UPDATE feeds
+SET claimed_until = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ReleaseFeedClaim(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedClaim, id)
+	return err
+}
+
+const renameFeed = `-- name: RenameFeed :one
+UPDATE feeds
+SET name = $2,
 updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, markFeedFetched, id)
+type RenameFeedParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, renameFeed, arg.ID, arg.Name)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ClaimedUntil,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const resetFeeds = `-- name: ResetFeeds :exec
+DELETE FROM feeds
+`
+
+func (q *Queries) ResetFeeds(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetFeeds)
+	return err
+}
+
+const setFeedFollowCategory = `-- name: SetFeedFollowCategory :execrows
+UPDATE feed_follows
+SET category_id = $3,
+updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowCategoryParams struct {
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	CategoryID uuid.NullUUID
+}
+
+func (q *Queries) SetFeedFollowCategory(ctx context.Context, arg SetFeedFollowCategoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowCategory, arg.UserID, arg.FeedID, arg.CategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedURL = `-- name: SetFeedURL :one
+UPDATE feeds
+SET url = $2,
+updated_at = NOW(),
+etag = NULL,
+last_modified = NULL,
+next_fetch_at = NULL,
+last_error = NULL,
+consecutive_failures = 0,
+disabled_at = NULL
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, claimed_until, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+`
+
+type SetFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) SetFeedURL(ctx context.Context, arg SetFeedURLParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedURL, arg.ID, arg.Url)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -285,6 +742,15 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, erro
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ClaimedUntil,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
 	)
 	return i, err
 }
@@ -295,7 +761,7 @@ DELETE FROM feed_follows
     WHERE feed_follows.feed_id = feeds.id
     AND feed_follows.user_id = $1
     AND feeds.name = $2
-    RETURNING feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id
+    RETURNING feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.category_id
 `
 
 type UnfollowParams struct {
@@ -307,3 +773,39 @@ func (q *Queries) Unfollow(ctx context.Context, arg UnfollowParams) error {
 	_, err := q.db.ExecContext(ctx, unfollow, arg.UserID, arg.Name)
 	return err
 }
+
+const updateFeedCacheHeaders = `-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET etag = $2,
+last_modified = $3
+WHERE id = $1
+`
+
+type UpdateFeedCacheHeadersParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) UpdateFeedCacheHeaders(ctx context.Context, arg UpdateFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCacheHeaders, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const updateFeedSchedule = `-- name: UpdateFeedSchedule :exec
+UPDATE feeds
+SET fetch_interval = $2,
+next_fetch_at = $3
+WHERE id = $1
+`
+
+type UpdateFeedScheduleParams struct {
+	ID            uuid.UUID
+	FetchInterval int32
+	NextFetchAt   sql.NullTime
+}
+
+func (q *Queries) UpdateFeedSchedule(ctx context.Context, arg UpdateFeedScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedSchedule, arg.ID, arg.FetchInterval, arg.NextFetchAt)
+	return err
+}
@@ -7,87 +7,206 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    NOW(),
-    NOW(),
-    $2,
-    $3,
-    $4,
-    $5,
-    $6
+const adoptLegacyPostGUID = `-- name: AdoptLegacyPostGUID :exec
+UPDATE posts
+SET guid = $1
+WHERE posts.feed_id = $2
+AND posts.url = $3
+AND posts.guid = posts.url
+AND NOT EXISTS (
+    SELECT 1
+    FROM posts AS existing
+    WHERE existing.feed_id = $2
+    AND existing.guid = $1
 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
 `
 
-type CreatePostParams struct {
-	ID          uuid.UUID
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+type AdoptLegacyPostGUIDParams struct {
+	Guid   string
+	FeedID uuid.UUID
+	Url    string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
-		arg.FeedID,
-	)
-	var i Post
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Title,
-		&i.Url,
-		&i.Description,
-		&i.PublishedAt,
-		&i.FeedID,
+func (q *Queries) AdoptLegacyPostGUID(ctx context.Context, arg AdoptLegacyPostGUIDParams) error {
+	_, err := q.db.ExecContext(ctx, adoptLegacyPostGUID, arg.Guid, arg.FeedID, arg.Url)
+	return err
+}
+
+const countPostsForUser = `-- name: CountPostsForUser :one
+SELECT COUNT(*)
+FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+AND (NOT $2::bool OR post_reads.read_at IS NULL)
+AND (NOT $3::bool OR post_reads.read_at < posts.updated_at)
+AND ($4::text IS NULL OR feeds.name = $4)
+AND ($5::timestamp IS NULL OR posts.published_at >= $5)
+AND ($6::timestamp IS NULL OR posts.published_at < $6)
+AND (
+    $7::text IS NULL
+    OR feed_follows.category_id IN (
+        SELECT categories.id
+        FROM categories
+        WHERE categories.user_id = feed_follows.user_id
+        AND categories.name = $7
+    )
+)
+`
+
+type CountPostsForUserParams struct {
+	UserID      uuid.UUID
+	UnreadOnly  bool
+	UpdatedOnly bool
+	FeedName    sql.NullString
+	Since       sql.NullTime
+	Until       sql.NullTime
+	Category    sql.NullString
+}
+
+func (q *Queries) CountPostsForUser(ctx context.Context, arg CountPostsForUserParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.UpdatedOnly,
+		arg.FeedName,
+		arg.Since,
+		arg.Until,
+		arg.Category,
 	)
-	return i, err
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteLegacyDuplicate = `-- name: DeleteLegacyDuplicate :execrows
+DELETE FROM posts
+WHERE posts.id = $1
+AND EXISTS (
+    SELECT 1
+    FROM posts AS legacy
+    WHERE legacy.feed_id = posts.feed_id
+    AND legacy.url = posts.url
+    AND legacy.guid = legacy.url
+    AND legacy.id <> posts.id
+)
+`
+
+func (q *Queries) DeleteLegacyDuplicate(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLegacyDuplicate, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getPostsForUsers = `-- name: GetPostsForUsers :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, feeds.name AS feed_name
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.published_at_estimated,
+    posts.guid,
+    posts.content_hash,
+    feeds.name AS feed_name,
+    post_reads.read_at
 FROM posts
 JOIN feeds ON posts.feed_id = feeds.id
 JOIN feed_follows ON feed_follows.feed_id = feeds.id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
 WHERE feed_follows.user_id = $1
-ORDER BY published_at DESC
-LIMIT $2
+AND (NOT $2::bool OR post_reads.read_at IS NULL)
+AND (NOT $3::bool OR post_reads.read_at < posts.updated_at)
+AND ($4::text IS NULL OR feeds.name = $4)
+AND ($5::timestamp IS NULL OR posts.published_at >= $5)
+AND ($6::timestamp IS NULL OR posts.published_at < $6)
+AND (
+    $7::text IS NULL
+    OR feed_follows.category_id IN (
+        SELECT categories.id
+        FROM categories
+        WHERE categories.user_id = feed_follows.user_id
+        AND categories.name = $7
+    )
+)
+AND (
+    $8::timestamp IS NULL
+    OR (
+        $9::bool
+        AND (posts.published_at, posts.id) > ($8, $10::uuid)
+    )
+    OR (
+        NOT $9::bool
+        AND (posts.published_at, posts.id) < ($8, $10::uuid)
+    )
+)
+ORDER BY
+    CASE WHEN $9::bool THEN posts.published_at END ASC,
+    CASE WHEN $9::bool THEN posts.id END ASC,
+    CASE WHEN NOT $9::bool THEN posts.published_at END DESC,
+    CASE WHEN NOT $9::bool THEN posts.id END DESC
+LIMIT $11
+OFFSET $12
 `
 
 type GetPostsForUsersParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID            uuid.UUID
+	UnreadOnly        bool
+	UpdatedOnly       bool
+	FeedName          sql.NullString
+	Since             sql.NullTime
+	Until             sql.NullTime
+	Category          sql.NullString
+	CursorPublishedAt sql.NullTime
+	OldestFirst       bool
+	CursorID          uuid.NullUUID
+	Limit             int32
+	Offset            int32
 }
 
 type GetPostsForUsersRow struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
-	FeedID      uuid.UUID
-	FeedName    string
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 string
+	ContentHash          string
+	FeedName             string
+	ReadAt               sql.NullTime
 }
 
 func (q *Queries) GetPostsForUsers(ctx context.Context, arg GetPostsForUsersParams) ([]GetPostsForUsersRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUsers, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUsers,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.UpdatedOnly,
+		arg.FeedName,
+		arg.Since,
+		arg.Until,
+		arg.Category,
+		arg.CursorPublishedAt,
+		arg.OldestFirst,
+		arg.CursorID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +223,11 @@ func (q *Queries) GetPostsForUsers(ctx context.Context, arg GetPostsForUsersPara
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.PublishedAtEstimated,
+			&i.Guid,
+			&i.ContentHash,
 			&i.FeedName,
+			&i.ReadAt,
 		); err != nil {
 			return nil, err
 		}
@@ -118,3 +241,342 @@ func (q *Queries) GetPostsForUsers(ctx context.Context, arg GetPostsForUsersPara
 	}
 	return items, nil
 }
+
+const getSavedPosts = `-- name: GetSavedPosts :many
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.published_at_estimated,
+    posts.guid,
+    posts.content_hash,
+    feeds.name AS feed_name,
+    saved_posts.created_at AS saved_at
+FROM saved_posts
+JOIN posts ON posts.id = saved_posts.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE saved_posts.user_id = $1
+ORDER BY saved_posts.created_at DESC
+`
+
+type GetSavedPostsRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 string
+	ContentHash          string
+	FeedName             string
+	SavedAt              time.Time
+}
+
+func (q *Queries) GetSavedPosts(ctx context.Context, userID uuid.UUID) ([]GetSavedPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedPosts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSavedPostsRow
+	for rows.Next() {
+		var i GetSavedPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.PublishedAtEstimated,
+			&i.Guid,
+			&i.ContentHash,
+			&i.FeedName,
+			&i.SavedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllPostsRead = `-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, NOW()
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.name = $2)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = EXCLUDED.read_at
+`
+
+type MarkAllPostsReadParams struct {
+	UserID   uuid.UUID
+	FeedName sql.NullString
+}
+
+func (q *Queries) MarkAllPostsRead(ctx context.Context, arg MarkAllPostsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markAllPostsRead, arg.UserID, arg.FeedName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markPostRead = `-- name: MarkPostRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, NOW()
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = EXCLUDED.read_at
+`
+
+type MarkPostReadParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) MarkPostRead(ctx context.Context, arg MarkPostReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostRead, arg.UserID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markPostUnread = `-- name: MarkPostUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2
+`
+
+type MarkPostUnreadParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) MarkPostUnread(ctx context.Context, arg MarkPostUnreadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostUnread, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const savePost = `-- name: SavePost :execrows
+INSERT INTO saved_posts (user_id, post_id, created_at)
+SELECT feed_follows.user_id, posts.id, NOW()
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET created_at = saved_posts.created_at
+`
+
+type SavePostParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) SavePost(ctx context.Context, arg SavePostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, savePost, arg.UserID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchPosts = `-- name: SearchPosts :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', $1)) AS rank
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.search_vector @@ websearch_to_tsquery('english', $1)
+AND (
+    $2::bool
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follows
+        WHERE feed_follows.feed_id = posts.feed_id
+        AND feed_follows.user_id = $3
+    )
+)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $4
+`
+
+type SearchPostsParams struct {
+	Query    string
+	AllFeeds bool
+	UserID   uuid.UUID
+	Limit    int32
+}
+
+type SearchPostsRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	PublishedAt time.Time
+	FeedName    string
+	Rank        float32
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts,
+		arg.Query,
+		arg.AllFeeds,
+		arg.UserID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unsavePost = `-- name: UnsavePost :execrows
+DELETE FROM saved_posts
+WHERE user_id = $1 AND post_id = $2
+`
+
+type UnsavePostParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) UnsavePost(ctx context.Context, arg UnsavePostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unsavePost, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertPost = `-- name: UpsertPost :one
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, published_at_estimated, feed_id, guid, content_hash)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+url = EXCLUDED.url,
+description = EXCLUDED.description,
+content_hash = EXCLUDED.content_hash,
+updated_at = NOW()
+WHERE posts.content_hash <> EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_estimated, guid, content_hash, (xmax = 0) AS inserted
+`
+
+type UpsertPostParams struct {
+	ID                   uuid.UUID
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	PublishedAtEstimated bool
+	FeedID               uuid.UUID
+	Guid                 string
+	ContentHash          string
+}
+
+type UpsertPostRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 string
+	ContentHash          string
+	Inserted             bool
+}
+
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (UpsertPostRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.ID,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.PublishedAtEstimated,
+		arg.FeedID,
+		arg.Guid,
+		arg.ContentHash,
+	)
+	var i UpsertPostRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.PublishedAtEstimated,
+		&i.Guid,
+		&i.ContentHash,
+		&i.Inserted,
+	)
+	return i, err
+}
@@ -0,0 +1,124 @@
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// A feed when XMLURL is set, otherwise a folder of nested outlines
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// One feed from a subscription list. Category is the folder it was filed
+// under, nested folders are joined with "/".
+type Subscription struct {
+	Title    string
+	URL      string
+	Category string
+}
+
+// Flattens the feeds of an OPML document, folders become categories
+func Parse(data []byte) ([]Subscription, error) {
+	var doc OPML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("could not unmarshal opml: %w", err)
+	}
+
+	var subs []Subscription
+	var walk func(outlines []Outline, category string)
+	walk = func(outlines []Outline, category string) {
+		for _, outline := range outlines {
+			title := strings.TrimSpace(outline.Text)
+			if title == "" {
+				title = strings.TrimSpace(outline.Title)
+			}
+
+			if outline.XMLURL == "" {
+				folder := title
+				if category != "" && folder != "" {
+					folder = category + "/" + folder
+				} else if folder == "" {
+					folder = category
+				}
+				walk(outline.Outlines, folder)
+				continue
+			}
+
+			if title == "" {
+				title = outline.XMLURL
+			}
+			subs = append(subs, Subscription{
+				Title:    title,
+				URL:      strings.TrimSpace(outline.XMLURL),
+				Category: category,
+			})
+		}
+	}
+	walk(doc.Body.Outlines, "")
+
+	return subs, nil
+}
+
+// OPML 2.0 document listing subs, grouped into one folder per category in
+// the order categories first appear
+func Marshal(title string, subs []Subscription, created time.Time) ([]byte, error) {
+	doc := OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: created.Format(time.RFC1123Z),
+		},
+	}
+
+	folders := make(map[string]int)
+	for _, sub := range subs {
+		outline := Outline{
+			Text:   sub.Title,
+			Title:  sub.Title,
+			Type:   "rss",
+			XMLURL: sub.URL,
+		}
+		if sub.Category == "" {
+			doc.Body.Outlines = append(doc.Body.Outlines, outline)
+			continue
+		}
+
+		i, ok := folders[sub.Category]
+		if !ok {
+			i = len(doc.Body.Outlines)
+			folders[sub.Category] = i
+			doc.Body.Outlines = append(doc.Body.Outlines, Outline{Text: sub.Category, Title: sub.Category})
+		}
+		doc.Body.Outlines[i].Outlines = append(doc.Body.Outlines[i].Outlines, outline)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal opml: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
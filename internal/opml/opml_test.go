@@ -0,0 +1,87 @@
+package opml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Loose" xmlUrl=" https://example.com/loose.xml " type="rss"/>
+    <outline title="Only a title" xmlUrl="https://example.com/title.xml"/>
+    <outline xmlUrl="https://example.com/untitled.xml"/>
+    <outline text="Tech">
+      <outline text="Go" xmlUrl="https://go.dev/blog/feed.atom"/>
+      <outline text="Databases">
+        <outline text="Postgres" xmlUrl="https://postgres.example/rss"/>
+      </outline>
+      <outline>
+        <outline text="Unnamed folder" xmlUrl="https://example.com/unnamed.xml"/>
+      </outline>
+    </outline>
+  </body>
+</opml>`
+
+	subs, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	want := []Subscription{
+		{Title: "Loose", URL: "https://example.com/loose.xml"},
+		{Title: "Only a title", URL: "https://example.com/title.xml"},
+		{Title: "https://example.com/untitled.xml", URL: "https://example.com/untitled.xml"},
+		{Title: "Go", URL: "https://go.dev/blog/feed.atom", Category: "Tech"},
+		{Title: "Postgres", URL: "https://postgres.example/rss", Category: "Tech/Databases"},
+		{Title: "Unnamed folder", URL: "https://example.com/unnamed.xml", Category: "Tech"},
+	}
+	if !reflect.DeepEqual(subs, want) {
+		t.Errorf("Parse =\n%+v\nwant\n%+v", subs, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("<opml><body>")); err == nil {
+		t.Errorf("Parse should reject truncated xml")
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	subs := []Subscription{
+		{Title: "Loose", URL: "https://example.com/loose.xml"},
+		{Title: "Go", URL: "https://go.dev/blog/feed.atom", Category: "tech"},
+		{Title: "Ampersand & co", URL: "https://example.com/rss?a=1&b=2"},
+		{Title: "Postgres", URL: "https://postgres.example/rss", Category: "tech"},
+		{Title: "News", URL: "https://news.example/rss", Category: "news"},
+	}
+	created := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+
+	data, err := Marshal("My feeds", subs, created)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	out := string(data)
+	if !strings.HasPrefix(out, `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("Marshal output is missing the xml header:\n%s", out)
+	}
+	for _, s := range []string{`<opml version="2.0">`, "<title>My feeds</title>", "<dateCreated>Tue, 05 Mar 2024 14:30:00 +0000</dateCreated>"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Marshal output is missing %s:\n%s", s, out)
+		}
+	}
+
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	// Feeds are grouped into one folder per category, in the order the
+	// categories first appear
+	want := []Subscription{subs[0], subs[1], subs[3], subs[2], subs[4]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v", got, want)
+	}
+}
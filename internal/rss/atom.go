@@ -0,0 +1,131 @@
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Title    AtomText    `xml:"title"`
+	Subtitle AtomText    `xml:"subtitle"`
+	Link     []AtomLink  `xml:"link"`
+	Entry    []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     AtomText   `xml:"title"`
+	Link      []AtomLink `xml:"link"`
+	Summary   AtomText   `xml:"summary"`
+	Content   AtomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// Atom text constructs are plain or escaped html in the element's text,
+// or inline markup when type is xhtml
+type AtomText struct {
+	Type     string `xml:"type,attr"`
+	CharData string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+// Markup of the text, xhtml loses the <div> it's required to be wrapped in
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return unwrapDiv(strings.TrimSpace(t.InnerXML))
+	}
+	return strings.TrimSpace(t.CharData)
+}
+
+// The text without markup, for titles
+func (t AtomText) Text() string {
+	if t.Type == "html" || t.Type == "xhtml" {
+		return stripTags(t.String())
+	}
+	return t.String()
+}
+
+func unwrapDiv(s string) string {
+	if !strings.HasPrefix(s, "<div") || !strings.HasSuffix(s, "</div>") {
+		return s
+	}
+	end := strings.Index(s, ">")
+	if end < 0 {
+		return s
+	}
+	return strings.TrimSpace(s[end+1 : len(s)-len("</div>")])
+}
+
+// Drops tags and collapses whitespace, entities are left for the caller
+// to unescape
+func stripTags(s string) string {
+	var buf strings.Builder
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag:
+			buf.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+func parseAtom(data []byte) (*RSSFeed, error) {
+	var atom AtomFeed
+	if err := xml.Unmarshal(data, &atom); err != nil {
+		return nil, fmt.Errorf("could not unmarshal atom: %w", err)
+	}
+
+	feed := &RSSFeed{}
+	feed.Channel.Title = atom.Title.Text()
+	feed.Channel.Link = alternateLink(atom.Link)
+	feed.Channel.Description = atom.Subtitle.String()
+
+	for _, entry := range atom.Entry {
+		// Summaries are what RSS descriptions usually hold, fall back to the full content
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			GUID:        entry.ID,
+			Title:       entry.Title.Text(),
+			Link:        alternateLink(entry.Link),
+			Description: description,
+			PubDate:     published,
+		})
+	}
+
+	return feed, nil
+}
+
+// Returns the rel="alternate" link, a missing rel means alternate. Other
+// links like self or enclosure aren't the post's page, so without an
+// alternate there's no link and the entry is identified by its id.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package rss
+
+import "testing"
+
+func TestParseFeedAtom(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="text">Example Atom</title>
+  <subtitle>An example feed</subtitle>
+  <link rel="self" href="https://example.com/atom.xml"/>
+  <link href="https://example.com/"/>
+  <entry>
+    <id>tag:example.com,2024:1</id>
+    <title>First</title>
+    <link rel="alternate" href="https://example.com/1"/>
+    <summary>Short</summary>
+    <content type="html">Long</content>
+    <published>2024-03-05T14:30:00Z</published>
+    <updated>2024-03-06T14:30:00Z</updated>
+  </entry>
+  <entry>
+    <id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>
+    <title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">Second <em>post</em></div></title>
+    <link rel="enclosure" href="https://example.com/2.mp3"/>
+    <summary type="xhtml">
+      <div xmlns="http://www.w3.org/1999/xhtml"><p>Summary</p></div>
+    </summary>
+    <content type="html">&lt;p&gt;Body&lt;/p&gt;</content>
+    <updated>2024-03-07T14:30:00Z</updated>
+  </entry>
+  <entry>
+    <id>tag:example.com,2024:3</id>
+    <title type="html">&lt;b&gt;Third&lt;/b&gt; &amp;amp; last</title>
+    <link href="https://example.com/3"/>
+    <content type="html">&lt;p&gt;Body&lt;/p&gt;</content>
+    <updated>2024-03-08T14:30:00Z</updated>
+  </entry>
+</feed>`
+
+	feed, err := parseFeed("application/atom+xml", []byte(data))
+	if err != nil {
+		t.Fatalf("parseFeed returned error: %v", err)
+	}
+	if feed.Channel.Title != "Example Atom" {
+		t.Errorf("title = %q, want Example Atom", feed.Channel.Title)
+	}
+	if feed.Channel.Link != "https://example.com/" {
+		t.Errorf("link = %q, want the alternate link", feed.Channel.Link)
+	}
+	if feed.Channel.Description != "An example feed" {
+		t.Errorf("description = %q, want the subtitle", feed.Channel.Description)
+	}
+
+	want := []RSSItem{
+		{GUID: "tag:example.com,2024:1", Title: "First", Link: "https://example.com/1", Description: "Short", PubDate: "2024-03-05T14:30:00Z"},
+		{
+			GUID:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
+			Title:       "Second post",
+			Description: "<p>Summary</p>",
+			PubDate:     "2024-03-07T14:30:00Z",
+		},
+		{GUID: "tag:example.com,2024:3", Title: "Third &amp; last", Link: "https://example.com/3", Description: "<p>Body</p>", PubDate: "2024-03-08T14:30:00Z"},
+	}
+	checkItems(t, feed.Channel.Item, want)
+}
@@ -0,0 +1,129 @@
+package rss
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layouts are tried in order after the weekday is stripped and any named
+// time zone is rewritten as a numeric offset. A day written as "2" also
+// accepts two digit days.
+var dateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"Jan 2 15:04:05 -0700 2006",
+	"Jan 2 15:04:05 2006",
+}
+
+// Go only knows the offset of zone abbreviations used by the local time
+// zone, any other abbreviation silently parses as UTC
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+	"BST":  "+0100",
+	"IST":  "+0530",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+}
+
+// ParseDate parses the publish date formats found in real-world feeds:
+// RFC 822/1123 with or without weekday, single digit days, named time
+// zones and ISO 8601. The result is in UTC.
+func ParseDate(value string) (time.Time, error) {
+	normalized := normalizeDate(value)
+	if normalized == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, normalized)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format: %q", value)
+}
+
+func normalizeDate(value string) string {
+	value = strings.TrimSpace(value)
+
+	// Weekdays add nothing and are often misspelled or wrong, drop them
+	if i := strings.Index(value, ","); i > 0 && isLetters(value[:i]) {
+		value = strings.TrimSpace(value[i+1:])
+	}
+
+	fields := strings.Fields(value)
+	// ANSIC and Unix dates start with a weekday but no comma
+	if len(fields) > 1 && isWeekday(fields[0]) {
+		fields = fields[1:]
+	}
+	// Unix dates put the zone before the year, RFC 822 at the end
+	if len(fields) > 1 {
+		for i, field := range fields {
+			if offset, ok := zoneOffsets[strings.ToUpper(field)]; ok {
+				fields[i] = offset
+			}
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+var weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Full or abbreviated weekday name such as "Mon", "Tues" or "Thursday"
+func isWeekday(s string) bool {
+	s = strings.ToLower(s)
+	if len(s) < 3 || !isLetters(s) {
+		return false
+	}
+	for _, day := range weekdays {
+		if strings.HasPrefix(day, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"RFC 1123Z", "Tue, 05 Mar 2024 14:30:00 +0000", want},
+		{"RFC 1123 with GMT", "Tue, 05 Mar 2024 14:30:00 GMT", want},
+		{"no weekday", "05 Mar 2024 14:30:00 +0000", want},
+		{"single digit day", "Tue, 5 Mar 2024 14:30:00 +0000", want},
+		{"misspelled weekday", "Tues, 5 Mar 2024 14:30:00 +0000", want},
+		{"named zone", "Tue, 05 Mar 2024 09:30:00 EST", want},
+		{"lowercase zone", "Tue, 05 Mar 2024 14:30:00 gmt", want},
+		{"offset", "Tue, 05 Mar 2024 16:30:00 +0200", want},
+		{"no seconds", "Tue, 05 Mar 2024 14:30 +0000", want},
+		{"two digit year", "Tue, 05 Mar 24 14:30:00 +0000", want},
+		{"full month", "5 March 2024 14:30:00 +0000", want},
+		{"no zone", "5 Mar 2024 14:30:00", want},
+		{"date only", "5 Mar 2024", time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"RFC 3339", "2024-03-05T14:30:00Z", want},
+		{"RFC 3339 with offset", "2024-03-05T16:30:00+02:00", want},
+		{"RFC 3339 fractional", "2024-03-05T14:30:00.123Z", want.Add(123 * time.Millisecond)},
+		{"ISO 8601 basic offset", "2024-03-05T16:30:00+0200", want},
+		{"ISO 8601 no seconds", "2024-03-05T14:30Z", want},
+		{"ISO 8601 no zone", "2024-03-05T14:30:00", want},
+		{"space separated", "2024-03-05 14:30:00", want},
+		{"space separated with offset", "2024-03-05 16:30:00 +0200", want},
+		{"ISO date only", "2024-03-05", time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"ANSIC", "Tue Mar  5 14:30:00 2024", want},
+		{"Unix date", "Tue Mar  5 07:30:00 MST 2024", want},
+		{"Unix date with offset", "Tue Mar 5 16:30:00 +0200 2024", want},
+		{"surrounding space", "  Tue, 05 Mar 2024 14:30:00 GMT \n", want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDate(tt.value)
+			if err != nil {
+				t.Fatalf("ParseDate(%q) returned error: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("ParseDate(%q) location = %v, want UTC", tt.value, got.Location())
+			}
+		})
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, value := range []string{"", "   ", "yesterday", "Tue, 32 Mar 2024 14:30:00 GMT", "2024-13-05"} {
+		if got, err := ParseDate(value); err == nil {
+			t.Errorf("ParseDate(%q) = %v, want error", value, got)
+		}
+	}
+}
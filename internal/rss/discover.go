@@ -0,0 +1,141 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A feed found by Discover
+type DiscoveredFeed struct {
+	URL   string
+	Title string
+}
+
+// Tried in order when a page doesn't advertise its feeds
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/feed.xml", "/index.xml"}
+
+// Plain application/json isn't accepted, WordPress uses it to link every
+// page to its REST API
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+}
+
+var (
+	linkTagRe   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	attributeRe = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// Finds the feeds behind a URL. A URL that is already a feed is returned
+// as is, otherwise the page's <link rel="alternate"> tags are used, and
+// failing that a few common feed paths on the same site are tried.
+func Discover(ctx context.Context, pageURL string) ([]DiscoveredFeed, error) {
+	data, contentType, base, err := fetchPage(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if feed, err := parseFeed(contentType, data); err == nil {
+		return []DiscoveredFeed{{
+			URL:   pageURL,
+			Title: html.UnescapeString(feed.Channel.Title),
+		}}, nil
+	}
+
+	if feeds := feedLinks(base, data); len(feeds) > 0 {
+		return feeds, nil
+	}
+
+	var feeds []DiscoveredFeed
+	for _, path := range commonFeedPaths {
+		candidate := base.ResolveReference(&url.URL{Path: path}).String()
+		feed, err := FetchFeed(ctx, candidate)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		feeds = append(feeds, DiscoveredFeed{URL: candidate, Title: feed.Channel.Title})
+	}
+	return feeds, nil
+}
+
+// Returns the body, its Content-Type and the URL it was served from after
+// redirects, which relative links resolve against
+func fetchPage(ctx context.Context, pageURL string) ([]byte, string, *url.URL, error) {
+	httpClient := http.Client{
+		Timeout: 10 * time.Second,
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("could not make request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gator")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("cannot read response body: %w", err)
+	}
+	return data, resp.Header.Get("Content-Type"), resp.Request.URL, nil
+}
+
+// Feeds advertised with <link rel="alternate" type="..."> in an HTML page,
+// in page order without duplicates
+func feedLinks(base *url.URL, page []byte) []DiscoveredFeed {
+	var feeds []DiscoveredFeed
+	seen := make(map[string]bool)
+	for _, tag := range linkTagRe.FindAll(page, -1) {
+		attrs := make(map[string]string)
+		for _, match := range attributeRe.FindAllSubmatch(tag, -1) {
+			value := string(match[2]) + string(match[3]) + string(match[4])
+			attrs[strings.ToLower(string(match[1]))] = html.UnescapeString(value)
+		}
+
+		if !hasToken(attrs["rel"], "alternate") || attrs["href"] == "" {
+			continue
+		}
+		mediaType, _, _ := mime.ParseMediaType(attrs["type"])
+		if !feedLinkTypes[mediaType] {
+			continue
+		}
+
+		href, err := base.Parse(strings.TrimSpace(attrs["href"]))
+		if err != nil || seen[href.String()] {
+			continue
+		}
+		seen[href.String()] = true
+		feeds = append(feeds, DiscoveredFeed{URL: href.String(), Title: attrs["title"]})
+	}
+	return feeds
+}
+
+// rel holds a space separated list of link types
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
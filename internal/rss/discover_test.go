@@ -0,0 +1,70 @@
+package rss
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFeedLinks(t *testing.T) {
+	base, err := url.Parse("https://example.com/blog/post")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		page string
+		want []DiscoveredFeed
+	}{
+		{
+			name: "rss and atom",
+			page: `<html><head>
+<link rel="alternate" type="application/rss+xml" title="Posts" href="https://example.com/rss.xml">
+<link rel="alternate" type="application/atom+xml" title="Atom" href="/atom.xml" />
+</head></html>`,
+			want: []DiscoveredFeed{
+				{URL: "https://example.com/rss.xml", Title: "Posts"},
+				{URL: "https://example.com/atom.xml", Title: "Atom"},
+			},
+		},
+		{
+			name: "relative href, single quotes and odd case",
+			page: `<LINK REL='Alternate' TYPE='application/feed+json; charset=utf-8' HREF='feed.json'>`,
+			want: []DiscoveredFeed{{URL: "https://example.com/blog/feed.json"}},
+		},
+		{
+			name: "unquoted attributes and entities",
+			page: `<link rel=alternate type=application/rss+xml title="Tom &amp; Jerry" href=/feed?a=1&amp;b=2>`,
+			want: []DiscoveredFeed{{URL: "https://example.com/feed?a=1&b=2", Title: "Tom & Jerry"}},
+		},
+		{
+			name: "rel with several tokens",
+			page: `<link rel="feed alternate" type="application/rss+xml" href="/rss">`,
+			want: []DiscoveredFeed{{URL: "https://example.com/rss"}},
+		},
+		{
+			name: "duplicates",
+			page: `<link rel="alternate" type="application/rss+xml" href="/rss">
+<link rel="alternate" type="application/rss+xml" href="https://example.com/rss">`,
+			want: []DiscoveredFeed{{URL: "https://example.com/rss"}},
+		},
+		{
+			name: "not feeds",
+			page: `<link rel="stylesheet" type="text/css" href="/style.css">
+<link rel="alternate" hreflang="fr" type="text/html" href="/fr/">
+<link rel="alternate" type="application/rss+xml">
+<link rel="icon" type="application/rss+xml" href="/rss">
+<link rel="alternate" type="application/json" href="https://example.com/wp-json/wp/v2/pages/2">`,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := feedLinks(base, []byte(tt.page))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("feedLinks =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
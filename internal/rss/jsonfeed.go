@@ -0,0 +1,85 @@
+package rss
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// Reports whether a response holds a JSON Feed, either by its
+// Content-Type or, for servers that send text/plain, by its first byte
+func isJSONFeed(contentType string, data []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/feed+json" || mediaType == "application/json" {
+		return true
+	}
+	trimmed := strings.TrimSpace(string(data))
+	return strings.HasPrefix(trimmed, "{")
+}
+
+func parseJSONFeed(data []byte) (*RSSFeed, error) {
+	var jsonFeed JSONFeed
+	if err := json.Unmarshal(data, &jsonFeed); err != nil {
+		return nil, fmt.Errorf("could not unmarshal json feed: %w", err)
+	}
+	// Plain JSON APIs also start with "{", only accept real JSON Feeds
+	if !strings.HasPrefix(jsonFeed.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("not a json feed: missing jsonfeed.org version")
+	}
+
+	feed := &RSSFeed{}
+	feed.Channel.Title = jsonFeed.Title
+	feed.Channel.Link = jsonFeed.HomePageURL
+	feed.Channel.Description = jsonFeed.Description
+
+	for _, item := range jsonFeed.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+
+		description := item.ContentHTML
+		if description == "" {
+			description = item.ContentText
+		}
+		if description == "" {
+			description = item.Summary
+		}
+
+		published := item.DatePublished
+		if published == "" {
+			published = item.DateModified
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			GUID:        item.ID,
+			Title:       item.Title,
+			Link:        link,
+			Description: description,
+			PubDate:     published,
+		})
+	}
+
+	return feed, nil
+}
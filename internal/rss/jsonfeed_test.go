@@ -0,0 +1,77 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFeedJSON(t *testing.T) {
+	data := `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Example JSON",
+  "home_page_url": "https://example.com/",
+  "description": "An example feed",
+  "items": [
+    {
+      "id": "1",
+      "url": "https://example.com/1",
+      "title": "First",
+      "content_html": "<p>Html</p>",
+      "content_text": "Text",
+      "date_published": "2024-03-05T14:30:00Z"
+    },
+    {
+      "id": "2",
+      "external_url": "https://other.example/2",
+      "title": "Second",
+      "summary": "Summary",
+      "date_modified": "2024-03-06T14:30:00Z"
+    }
+  ]
+}`
+
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{"feed+json", "application/feed+json; charset=utf-8"},
+		{"json", "application/json"},
+		{"sniffed", "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := parseFeed(tt.contentType, []byte(data))
+			if err != nil {
+				t.Fatalf("parseFeed returned error: %v", err)
+			}
+			if feed.Channel.Title != "Example JSON" || feed.Channel.Link != "https://example.com/" {
+				t.Errorf("channel = %q %q, want Example JSON https://example.com/", feed.Channel.Title, feed.Channel.Link)
+			}
+
+			want := []RSSItem{
+				{GUID: "1", Title: "First", Link: "https://example.com/1", Description: "<p>Html</p>", PubDate: "2024-03-05T14:30:00Z"},
+				{GUID: "2", Title: "Second", Link: "https://other.example/2", Description: "Summary", PubDate: "2024-03-06T14:30:00Z"},
+			}
+			checkItems(t, feed.Channel.Item, want)
+		})
+	}
+}
+
+func TestParseFeedJSONNotAFeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"plain json api", `{"status": "ok"}`, "missing jsonfeed.org version"},
+		{"broken json", `{"version": `, "could not unmarshal json feed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFeed("application/json", []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseFeed error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// RSS 1.0 keeps items as siblings of the channel under <rdf:RDF>
+type RDFFeed struct {
+	XMLName xml.Name `xml:"RDF"`
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+	} `xml:"channel"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+func parseRDF(data []byte) (*RSSFeed, error) {
+	var rdf RDFFeed
+	if err := xml.Unmarshal(data, &rdf); err != nil {
+		return nil, fmt.Errorf("could not unmarshal rdf: %w", err)
+	}
+
+	feed := &RSSFeed{}
+	feed.Channel.Title = rdf.Channel.Title
+	feed.Channel.Link = rdf.Channel.Link
+	feed.Channel.Description = rdf.Channel.Description
+
+	for _, item := range rdf.Item {
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			GUID:        item.About,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.Date,
+		})
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,41 @@
+package rss
+
+import "testing"
+
+func TestParseFeedRDF(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<rdf:RDF
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns="http://purl.org/rss/1.0/">
+  <channel rdf:about="https://example.com/rss">
+    <title>Example RDF</title>
+    <link>https://example.com/</link>
+    <description>An example feed</description>
+    <items>
+      <rdf:Seq>
+        <rdf:li resource="https://example.com/1"/>
+      </rdf:Seq>
+    </items>
+  </channel>
+  <item rdf:about="https://example.com/1">
+    <title>First</title>
+    <link>https://example.com/1</link>
+    <description>One</description>
+    <dc:date>2024-03-05T14:30:00Z</dc:date>
+  </item>
+</rdf:RDF>`
+
+	feed, err := parseFeed("application/rdf+xml", []byte(data))
+	if err != nil {
+		t.Fatalf("parseFeed returned error: %v", err)
+	}
+	if feed.Channel.Title != "Example RDF" || feed.Channel.Link != "https://example.com/" {
+		t.Errorf("channel = %q %q, want Example RDF https://example.com/", feed.Channel.Title, feed.Channel.Link)
+	}
+
+	want := []RSSItem{
+		{GUID: "https://example.com/1", Title: "First", Link: "https://example.com/1", Description: "One", PubDate: "2024-03-05T14:30:00Z"},
+	}
+	checkItems(t, feed.Channel.Item, want)
+}
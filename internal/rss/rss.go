@@ -1,12 +1,17 @@
 package rss
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/xml"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,46 +20,136 @@ type RSSFeed struct {
 		Title       string    `xml:"title"`
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
+		TTL         string    `xml:"ttl"`
+		SkipHours   []string  `xml:"skipHours>hour"`
 		Item        []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
+	GUID        string `xml:"guid"`
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	DCDate      string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+// Identifies the item within its feed. Not every feed gives items a guid or
+// even a link, so fall back to the link and then to a hash of the content.
+func (item RSSItem) ID() string {
+	if guid := strings.TrimSpace(item.GUID); guid != "" {
+		return guid
+	}
+	if link := strings.TrimSpace(item.Link); link != "" {
+		return link
+	}
+	sum := sha256.Sum256([]byte(item.Title + "\x00" + item.Description))
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint of the item's editable content, used to notice when a
+// publisher changes a post after it was first stored. The 0x1f separator
+// keeps it reproducible in SQL, see 012_post_updates.sql.
+func (item RSSItem) Hash() string {
+	sum := sha256.Sum256([]byte(item.Title + "\x1f" + item.Link + "\x1f" + item.Description))
+	return hex.EncodeToString(sum[:])
+}
+
+// Minimum time the publisher asks clients to cache the channel for, zero
+// when the feed doesn't say
+func (f *RSSFeed) TTL() time.Duration {
+	minutes, err := strconv.Atoi(strings.TrimSpace(f.Channel.TTL))
+	if err != nil || minutes < 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// Hours of the day (UTC) the publisher asks clients not to fetch in
+func (f *RSSFeed) SkipHours() []int {
+	var hours []int
+	for _, h := range f.Channel.SkipHours {
+		hour, err := strconv.Atoi(strings.TrimSpace(h))
+		if err != nil || hour < 0 || hour > 24 {
+			continue
+		}
+		hours = append(hours, hour)
+	}
+	return hours
+}
+
+// Result of a conditional fetch. Feed is nil when the server answered
+// 304 Not Modified.
+type FetchResult struct {
+	Feed         *RSSFeed
+	NotModified  bool
+	ETag         string
+	LastModified string
 }
 
 func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+	result, err := FetchFeedConditional(ctx, feedURL, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Feed, nil
+}
+
+// Sends If-None-Match / If-Modified-Since when validators from a previous
+// fetch are given, so unchanged feeds aren't downloaded again
+func FetchFeedConditional(ctx context.Context, feedURL, etag, lastModified string) (FetchResult, error) {
 	httpClient := http.Client{
 		Timeout: 10 * time.Second,
 	}
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("could not make request: %w", err)
+		return FetchResult{}, fmt.Errorf("could not make request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", "gator")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return FetchResult{}, err
 	}
 	defer resp.Body.Close()
 
+	result := FetchResult{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		// A 304 may omit the validators, keep the ones we sent
+		if result.ETag == "" {
+			result.ETag = etag
+		}
+		if result.LastModified == "" {
+			result.LastModified = lastModified
+		}
+		result.NotModified = true
+		return result, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return FetchResult{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read response body: %w", err)
+		return FetchResult{}, fmt.Errorf("cannot read response body: %w", err)
 	}
 
-	var feed RSSFeed
-	err = xml.Unmarshal(data, &feed)
+	feed, err := parseFeed(resp.Header.Get("Content-Type"), data)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal xml: %w", err)
+		return FetchResult{}, err
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
@@ -66,5 +161,56 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 		feed.Channel.Item[i] = item
 	}
 
-	return &feed, nil
+	result.Feed = feed
+	return result, nil
+}
+
+// Picks a parser based on the Content-Type and the document's root
+// element, every format is normalized into an RSSFeed
+func parseFeed(contentType string, data []byte) (*RSSFeed, error) {
+	if isJSONFeed(contentType, data) {
+		return parseJSONFeed(data)
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal xml: %w", err)
+	}
+
+	switch root.Local {
+	case "feed":
+		return parseAtom(data)
+	case "RDF":
+		return parseRDF(data)
+	case "rss":
+		var feed RSSFeed
+		err = xml.Unmarshal(data, &feed)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal xml: %w", err)
+		}
+
+		// Some RSS 2.0 feeds use Dublin Core dates instead of pubDate
+		for i, item := range feed.Channel.Item {
+			if item.PubDate == "" {
+				feed.Channel.Item[i].PubDate = item.DCDate
+			}
+		}
+		return &feed, nil
+	default:
+		// Usually an HTML page, e.g. a site's homepage instead of its feed
+		return nil, fmt.Errorf("not a feed: document root is <%s>", root.Local)
+	}
+}
+
+func rootElement(data []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
 }
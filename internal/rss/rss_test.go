@@ -0,0 +1,107 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFeedRSS(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Example</title>
+    <link>https://example.com/</link>
+    <description>An example feed</description>
+    <ttl>60</ttl>
+    <skipHours><hour>1</hour><hour>2</hour></skipHours>
+    <item>
+      <guid>post-1</guid>
+      <title>First</title>
+      <link>https://example.com/1</link>
+      <description>One</description>
+      <pubDate>Tue, 05 Mar 2024 14:30:00 GMT</pubDate>
+    </item>
+    <item>
+      <title>Second</title>
+      <link>https://example.com/2</link>
+      <dc:date>2024-03-06T10:00:00Z</dc:date>
+    </item>
+  </channel>
+</rss>`
+
+	feed, err := parseFeed("application/rss+xml", []byte(data))
+	if err != nil {
+		t.Fatalf("parseFeed returned error: %v", err)
+	}
+	if feed.Channel.Title != "Example" || feed.Channel.Link != "https://example.com/" {
+		t.Errorf("channel = %q %q, want Example https://example.com/", feed.Channel.Title, feed.Channel.Link)
+	}
+	if got := feed.TTL().Minutes(); got != 60 {
+		t.Errorf("TTL = %v minutes, want 60", got)
+	}
+	if got := feed.SkipHours(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("SkipHours = %v, want [1 2]", got)
+	}
+
+	want := []RSSItem{
+		{GUID: "post-1", Title: "First", Link: "https://example.com/1", Description: "One", PubDate: "Tue, 05 Mar 2024 14:30:00 GMT"},
+		{Title: "Second", Link: "https://example.com/2", PubDate: "2024-03-06T10:00:00Z", DCDate: "2024-03-06T10:00:00Z"},
+	}
+	checkItems(t, feed.Channel.Item, want)
+}
+
+func TestParseFeedNotAFeed(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		wantErr     string
+	}{
+		{"html page", "text/html", "<!DOCTYPE html><html><head><title>Home</title></head></html>", "document root is <html>"},
+		{"broken xml", "application/xml", "not xml at all", "could not unmarshal xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFeed(tt.contentType, []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseFeed error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRSSItemID(t *testing.T) {
+	tests := []struct {
+		name string
+		item RSSItem
+		want string
+	}{
+		{"guid", RSSItem{GUID: " tag:example.com,2024:1 ", Link: "https://example.com/1"}, "tag:example.com,2024:1"},
+		{"link", RSSItem{Link: "https://example.com/1"}, "https://example.com/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.ID(); got != tt.want {
+				t.Errorf("ID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	a := RSSItem{Title: "Untitled", Description: "a"}
+	b := RSSItem{Title: "Untitled", Description: "b"}
+	if len(a.ID()) != 64 || a.ID() == b.ID() {
+		t.Errorf("items without guid or link should get a content hash, got %q and %q", a.ID(), b.ID())
+	}
+}
+
+func checkItems(t *testing.T, got, want []RSSItem) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package schedule
+
+import "time"
+
+const (
+	DefaultInterval = time.Hour
+	MinInterval     = 15 * time.Minute
+	MaxInterval     = 24 * time.Hour
+)
+
+// Next adapts a feed's fetch interval to how active it is and returns the
+// new interval along with when the feed is next due. Feeds that produced new
+// posts are polled twice as often, quiet feeds back off by half again. The
+// publisher's ttl is a lower bound and fetches never land in a skip hour.
+func Next(now time.Time, current time.Duration, newPosts int, ttl time.Duration, skipHours []int) (time.Duration, time.Time) {
+	interval := current
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	if newPosts > 0 {
+		interval /= 2
+	} else {
+		interval += interval / 2
+	}
+	interval = max(interval, MinInterval, min(ttl, MaxInterval))
+	interval = min(interval, MaxInterval)
+
+	return interval, skip(now.Add(interval), skipHours)
+}
+
+// Backoff returns when a feed that has failed failures times in a row should
+// be retried. The delay starts at the feed's interval and doubles with each
+// consecutive failure, up to MaxInterval.
+func Backoff(now time.Time, interval time.Duration, failures int) time.Time {
+	delay := max(interval, MinInterval)
+	for i := 1; i < failures && delay < MaxInterval; i++ {
+		delay *= 2
+	}
+	return now.Add(min(delay, MaxInterval))
+}
+
+// Moves t to the start of the next hour (UTC) that isn't skipped
+func skip(t time.Time, skipHours []int) time.Time {
+	skipped := make(map[int]bool, len(skipHours))
+	for _, hour := range skipHours {
+		skipped[hour%24] = true
+	}
+	if len(skipped) == 24 {
+		return t
+	}
+
+	t = t.UTC()
+	for skipped[t.Hour()] {
+		t = t.Truncate(time.Hour).Add(time.Hour)
+	}
+	return t
+}
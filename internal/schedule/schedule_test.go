@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	now := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		current      time.Duration
+		newPosts     int
+		ttl          time.Duration
+		skipHours    []int
+		wantInterval time.Duration
+		wantNext     time.Time
+	}{
+		{"default with new posts", 0, 3, 0, nil, 30 * time.Minute, now.Add(30 * time.Minute)},
+		{"default without new posts", 0, 0, 0, nil, 90 * time.Minute, now.Add(90 * time.Minute)},
+		{"quiet feed backs off", 2 * time.Hour, 0, 0, nil, 3 * time.Hour, now.Add(3 * time.Hour)},
+		{"busy feed floors at min", 20 * time.Minute, 1, 0, nil, MinInterval, now.Add(MinInterval)},
+		{"quiet feed caps at max", 20 * time.Hour, 0, 0, nil, MaxInterval, now.Add(MaxInterval)},
+		{"ttl is a lower bound", time.Hour, 1, 3 * time.Hour, nil, 3 * time.Hour, now.Add(3 * time.Hour)},
+		{"ttl above max is capped", time.Hour, 1, 48 * time.Hour, nil, MaxInterval, now.Add(MaxInterval)},
+		{"skip hours", time.Hour, 1, 0, []int{10, 11}, 30 * time.Minute, time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)},
+		{"skip hour 24 is midnight", 28 * time.Hour, 1, 0, []int{24}, 14 * time.Hour, time.Date(2024, time.March, 6, 1, 0, 0, 0, time.UTC)},
+		{"skip wraps past midnight", 9 * time.Hour, 0, 0, []int{23, 0, 1}, 13*time.Hour + 30*time.Minute, time.Date(2024, time.March, 6, 2, 0, 0, 0, time.UTC)},
+		{"every hour skipped is ignored", time.Hour, 1, 0, allHours(), 30 * time.Minute, now.Add(30 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, next := Next(now, tt.current, tt.newPosts, tt.ttl, tt.skipHours)
+			if interval != tt.wantInterval {
+				t.Errorf("interval = %v, want %v", interval, tt.wantInterval)
+			}
+			if !next.Equal(tt.wantNext) {
+				t.Errorf("next = %v, want %v", next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func allHours() []int {
+	hours := make([]int, 24)
+	for i := range hours {
+		hours[i] = i
+	}
+	return hours
+}
+
+func TestBackoff(t *testing.T) {
+	now := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{"first failure waits one interval", time.Hour, 1, time.Hour},
+		{"second failure doubles", time.Hour, 2, 2 * time.Hour},
+		{"fourth failure", time.Hour, 4, 8 * time.Hour},
+		{"capped at max", time.Hour, 10, MaxInterval},
+		{"short interval floors at min", time.Minute, 1, MinInterval},
+		{"unset interval floors at min", 0, 2, 2 * MinInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Backoff(now, tt.interval, tt.failures)
+			if want := now.Add(tt.want); !got.Equal(want) {
+				t.Errorf("Backoff(%v, %d) = %v, want %v", tt.interval, tt.failures, got, want)
+			}
+		})
+	}
+}
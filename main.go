@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	_ "github.com/lib/pq"
 
@@ -14,11 +16,37 @@ import (
 )
 
 func main() {
+	commands := cli.NewCommands()
+
 	if len(os.Args) < 2 {
-		fmt.Println("Expected 'agg', 'login', 'register' command")
+		commands.WriteHelp(os.Stderr)
+		os.Exit(1)
+	}
+
+	// The first SIGINT/SIGTERM cancels ctx so commands can wind down, a
+	// second one falls through to the default handler and kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	command := cli.NewCommand(ctx, os.Args[1:])
+	if command.Name == "" {
+		commands.WriteHelp(os.Stderr)
 		os.Exit(1)
 	}
 
+	// Help and standalone commands need neither the config file nor a
+	// database
+	if !commands.NeedsState(command) {
+		if err := commands.Run(nil, command); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Read the config file
 	cfg, err := config.Read()
 	if err != nil {
@@ -36,29 +64,11 @@ func main() {
 
 	newState := &cli.State{
 		DB:     dbQueries,
+		Conn:   db,
 		Config: &cfg,
 	}
 
-	cmd := cli.Commands{}
-	cmd.Register("register", cli.HandlerRegister)
-	cmd.Register("login", cli.HandlerLogin)
-	cmd.Register("reset", cli.HandlerReset)
-	cmd.Register("users", cli.HandlerGetUsers)
-	cmd.Register("agg", cli.HandlerAgg)
-	cmd.Register("addfeed", cli.MiddlewareLoggedIn(cli.HandlerAddFeed))
-	cmd.Register("feeds", cli.HandlerFeeds)
-	cmd.Register("follow", cli.MiddlewareLoggedIn(cli.HandlerFollow))
-	cmd.Register("following", cli.MiddlewareLoggedIn(cli.HandlerFollowing))
-	cmd.Register("unfollow", cli.MiddlewareLoggedIn(cli.HandlerUnfollow))
-	cmd.Register("update", cli.MiddlewareLoggedIn(cli.HandlerUpdate))
-	cmd.Register("browse", cli.MiddlewareLoggedIn(cli.HandlerBrowse))
-
-	command := cli.Command{
-		Name: os.Args[1],
-		Args: os.Args[2:],
-	}
-
-	if err := cmd.Run(newState, command); err != nil {
+	if err := commands.Run(newState, command); err != nil {
 		log.Fatal(err)
 	}
 
@@ -0,0 +1,24 @@
+-- name: CreateCategory :one
+INSERT INTO categories (id, created_at, user_id, name)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, name) DO NOTHING
+RETURNING *;
+
+-- name: EnsureCategory :one
+INSERT INTO categories (id, created_at, user_id, name)
+VALUES (gen_random_uuid(), NOW(), $1, $2)
+ON CONFLICT (user_id, name) DO UPDATE
+SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: GetCategoriesForUser :many
+SELECT categories.*, COUNT(feed_follows.id) AS feed_count
+FROM categories
+LEFT JOIN feed_follows ON feed_follows.category_id = categories.id
+WHERE categories.user_id = $1
+GROUP BY categories.id
+ORDER BY categories.name;
+
+-- name: DeleteCategory :execrows
+DELETE FROM categories
+WHERE user_id = $1 AND name = $2;
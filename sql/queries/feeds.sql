@@ -17,13 +17,13 @@ SELECT
     feeds.url AS feed_url,
     users.name AS user_name
 FROM feeds
-JOIN users
+LEFT JOIN users
 ON users.id = feeds.user_id;
 
 -- name: CreateFeedFollow :many
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-    VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
+    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, category_id)
+    VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3)
     RETURNING *
 )
 
@@ -37,6 +37,14 @@ ON inserted_feed_follow.feed_id = feeds.id
 INNER JOIN users
 ON inserted_feed_follow.user_id = users.id;
 
+-- name: ResetFeeds :exec
+DELETE FROM feeds;
+
+-- name: GetFeedByURL :one
+SELECT *
+FROM feeds
+WHERE url = $1;
+
 -- name: GetFeedFollow :one
 SELECT *
 FROM feed_follows
@@ -46,13 +54,24 @@ WHERE user_id = $1 AND feed_id = $2;
 SELECT 
     feed_follows.*,
     feeds.name AS feed_name,
-    users.name AS user_name
+    feeds.url AS feed_url,
+    users.name AS user_name,
+    categories.name AS category_name
 FROM feed_follows
 INNER JOIN feeds
 ON feed_follows.feed_id = feeds.id
 INNER JOIN users
 ON feed_follows.user_id = users.id
-WHERE feed_follows.user_id = $1;
+LEFT JOIN categories
+ON feed_follows.category_id = categories.id
+WHERE feed_follows.user_id = $1
+ORDER BY categories.name NULLS FIRST, feeds.name;
+
+-- name: SetFeedFollowCategory :execrows
+UPDATE feed_follows
+SET category_id = $3,
+updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
 
 -- name: Unfollow :exec
 DELETE FROM feed_follows
@@ -69,8 +88,110 @@ updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
--- name: GetNextFeedToFetch :one
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET claimed_until = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+WHERE id IN (
+    SELECT id
+    FROM feeds
+    WHERE disabled_at IS NULL
+    AND (claimed_until IS NULL OR claimed_until < NOW())
+    AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    ORDER BY next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseFeedClaim :exec
+UPDATE feeds
+SET claimed_until = NULL
+WHERE id = $1;
+
+-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET etag = $2,
+last_modified = $3
+WHERE id = $1;
+
+-- name: UpdateFeedSchedule :exec
+UPDATE feeds
+SET fetch_interval = $2,
+next_fetch_at = $3
+WHERE id = $1;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_error = NULL,
+consecutive_failures = 0,
+last_success_at = NOW()
+WHERE id = $1;
+
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET last_error = sqlc.arg(last_error),
+consecutive_failures = consecutive_failures + 1,
+next_fetch_at = sqlc.arg(next_fetch_at),
+disabled_at = CASE
+    WHEN consecutive_failures + 1 >= sqlc.arg(max_failures)::int THEN NOW()
+    ELSE disabled_at
+END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetBrokenFeeds :many
+SELECT *
+FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY disabled_at IS NULL, consecutive_failures DESC;
+
+-- name: GetFeedsByName :many
 SELECT *
 FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
+WHERE name = $1
+ORDER BY created_at;
+
+-- name: RenameFeed :one
+UPDATE feeds
+SET name = $2,
+updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedURL :one
+UPDATE feeds
+SET url = $2,
+updated_at = NOW(),
+etag = NULL,
+last_modified = NULL,
+next_fetch_at = NULL,
+last_error = NULL,
+consecutive_failures = 0,
+disabled_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: GetFeedStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS follower_count,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = $1) AS post_count,
+    (
+        SELECT COUNT(*)
+        FROM saved_posts
+        JOIN posts ON posts.id = saved_posts.post_id
+        WHERE posts.feed_id = $1
+    ) AS saved_count;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: EnableFeeds :many
+UPDATE feeds
+SET disabled_at = NULL,
+consecutive_failures = 0,
+last_error = NULL,
+next_fetch_at = NULL,
+updated_at = NOW()
+WHERE name = $1 OR url = $1
+RETURNING *;
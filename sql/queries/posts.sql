@@ -1,5 +1,5 @@
--- name: CreatePost :one
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
+-- name: UpsertPost :one
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, published_at_estimated, feed_id, guid, content_hash)
 VALUES (
     $1,
     NOW(),
@@ -8,15 +8,197 @@ VALUES (
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8,
+    $9
 )
-RETURNING *;
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+url = EXCLUDED.url,
+description = EXCLUDED.description,
+content_hash = EXCLUDED.content_hash,
+updated_at = NOW()
+WHERE posts.content_hash <> EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_estimated, guid, content_hash, (xmax = 0) AS inserted;
+
+-- name: AdoptLegacyPostGUID :exec
+UPDATE posts
+SET guid = sqlc.arg(guid)
+WHERE posts.feed_id = sqlc.arg(feed_id)
+AND posts.url = sqlc.arg(url)
+AND posts.guid = posts.url
+AND NOT EXISTS (
+    SELECT 1
+    FROM posts AS existing
+    WHERE existing.feed_id = sqlc.arg(feed_id)
+    AND existing.guid = sqlc.arg(guid)
+);
+
+-- name: DeleteLegacyDuplicate :execrows
+DELETE FROM posts
+WHERE posts.id = $1
+AND EXISTS (
+    SELECT 1
+    FROM posts AS legacy
+    WHERE legacy.feed_id = posts.feed_id
+    AND legacy.url = posts.url
+    AND legacy.guid = legacy.url
+    AND legacy.id <> posts.id
+);
 
 -- name: GetPostsForUsers :many
-SELECT posts.*, feeds.name AS feed_name
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.published_at_estimated,
+    posts.guid,
+    posts.content_hash,
+    feeds.name AS feed_name,
+    post_reads.read_at
+FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (NOT sqlc.arg(unread_only)::bool OR post_reads.read_at IS NULL)
+AND (NOT sqlc.arg(updated_only)::bool OR post_reads.read_at < posts.updated_at)
+AND (sqlc.narg(feed_name)::text IS NULL OR feeds.name = sqlc.narg(feed_name))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until))
+AND (
+    sqlc.narg(category)::text IS NULL
+    OR feed_follows.category_id IN (
+        SELECT categories.id
+        FROM categories
+        WHERE categories.user_id = feed_follows.user_id
+        AND categories.name = sqlc.narg(category)
+    )
+)
+AND (
+    sqlc.narg(cursor_published_at)::timestamp IS NULL
+    OR (
+        sqlc.arg(oldest_first)::bool
+        AND (posts.published_at, posts.id) > (sqlc.narg(cursor_published_at), sqlc.narg(cursor_id)::uuid)
+    )
+    OR (
+        NOT sqlc.arg(oldest_first)::bool
+        AND (posts.published_at, posts.id) < (sqlc.narg(cursor_published_at), sqlc.narg(cursor_id)::uuid)
+    )
+)
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first)::bool THEN posts.published_at END ASC,
+    CASE WHEN sqlc.arg(oldest_first)::bool THEN posts.id END ASC,
+    CASE WHEN NOT sqlc.arg(oldest_first)::bool THEN posts.published_at END DESC,
+    CASE WHEN NOT sqlc.arg(oldest_first)::bool THEN posts.id END DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*)
 FROM posts
 JOIN feeds ON posts.feed_id = feeds.id
 JOIN feed_follows ON feed_follows.feed_id = feeds.id
-WHERE feed_follows.user_id = $1
-ORDER BY published_at DESC
-LIMIT $2;
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (NOT sqlc.arg(unread_only)::bool OR post_reads.read_at IS NULL)
+AND (NOT sqlc.arg(updated_only)::bool OR post_reads.read_at < posts.updated_at)
+AND (sqlc.narg(feed_name)::text IS NULL OR feeds.name = sqlc.narg(feed_name))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until))
+AND (
+    sqlc.narg(category)::text IS NULL
+    OR feed_follows.category_id IN (
+        SELECT categories.id
+        FROM categories
+        WHERE categories.user_id = feed_follows.user_id
+        AND categories.name = sqlc.narg(category)
+    )
+);
+
+-- name: MarkPostRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, NOW()
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = EXCLUDED.read_at;
+
+-- name: MarkPostUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, NOW()
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed_name)::text IS NULL OR feeds.name = sqlc.narg(feed_name))
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = EXCLUDED.read_at;
+
+-- name: SavePost :execrows
+INSERT INTO saved_posts (user_id, post_id, created_at)
+SELECT feed_follows.user_id, posts.id, NOW()
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET created_at = saved_posts.created_at;
+
+-- name: UnsavePost :execrows
+DELETE FROM saved_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetSavedPosts :many
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.published_at_estimated,
+    posts.guid,
+    posts.content_hash,
+    feeds.name AS feed_name,
+    saved_posts.created_at AS saved_at
+FROM saved_posts
+JOIN posts ON posts.id = saved_posts.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE saved_posts.user_id = $1
+ORDER BY saved_posts.created_at DESC;
+
+-- name: SearchPosts :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+AND (
+    sqlc.arg(all_feeds)::bool
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follows
+        WHERE feed_follows.feed_id = posts.feed_id
+        AND feed_follows.user_id = sqlc.arg(user_id)
+    )
+)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+
+-- Must match rss.RSSItem.Hash so existing posts aren't reported as edited
+UPDATE posts
+SET content_hash = encode(sha256(convert_to(title || chr(31) || url || chr(31) || description, 'UTF8')), 'hex');
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN content_hash;
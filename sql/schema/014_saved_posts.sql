@@ -0,0 +1,12 @@
+-- +goose Up
+-- Saved posts must never be deleted for being old, anything that cleans up
+-- posts has to skip the ones with a row here
+CREATE TABLE saved_posts(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE saved_posts;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Lets DeleteLegacyDuplicate and AdoptLegacyPostGUID find posts still keyed by their url without
+-- scanning the feed's posts
+CREATE INDEX posts_legacy_guid_idx ON posts (feed_id, url) WHERE guid = url;
+
+-- +goose Down
+DROP INDEX posts_legacy_guid_idx;